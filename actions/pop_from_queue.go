@@ -36,22 +36,20 @@ type PopFromQueue struct {
 }
 
 func (n *PopFromQueue) Tick() core.NodeStatus {
-	var queue *core.ProtectedQueue
-	v, err := n.GetInput("queue", queue)
+	queue, err := core.GetInput[*core.ProtectedQueue](n, "queue")
 	if err != nil {
 		panic(err)
 	}
-	queue = v.(*core.ProtectedQueue)
 	if queue != nil {
 		queue.Mtx.Lock()
 		defer queue.Mtx.Unlock()
-		items := queue.Items
+		items := &queue.Items
 		if items.Len() == 0 {
 			return core.NodeStatus_FAILURE
 		} else {
 			val := items.Front()
 			items.Remove(val)
-			n.SetOutput("popped_item", val)
+			n.SetOutput("popped_item", val.Value)
 			return core.NodeStatus_SUCCESS
 		}
 	} else {
@@ -72,7 +70,7 @@ func (n *PopFromQueue) Tick() core.NodeStatus {
  */
 
 func NewQueueSize(name string, config *core.NodeConfig, args ...interface{}) core.ITreeNode {
-	return &PopFromQueue{
+	return &QueueSize{
 		SyncActionNode: core.NewSyncActionNode(name, config),
 	}
 }
@@ -82,16 +80,14 @@ type QueueSize struct {
 }
 
 func (n *QueueSize) Tick() core.NodeStatus {
-	var queue *core.ProtectedQueue
-	r, err := n.GetInput("queue", queue)
+	queue, err := core.GetInput[*core.ProtectedQueue](n, "queue")
 	if err != nil {
 		panic(err)
 	}
-	queue = r.(*core.ProtectedQueue)
 	if queue != nil {
 		queue.Mtx.Lock()
 		defer queue.Mtx.Unlock()
-		items := queue.Items
+		items := &queue.Items
 
 		if items.Len() == 0 {
 			return core.NodeStatus_FAILURE
@@ -1,9 +1,13 @@
 package actions
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"log"
+)
 
 func init() {
-
+	core.SetPorts(&ScriptCondition{}, core.InputPort("code", "Piece of code that can be parsed. Must return false or true"))
 }
 
 type ScriptCondition struct {
@@ -15,12 +19,14 @@ type ScriptCondition struct {
 func NewScriptCondition(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
 	n := &ScriptCondition{ConditionNode: core.NewConditionNode(name, cfg)}
 	n.SetRegistrationID("ScriptCondition")
-	n.loadExecutor()
 	return n
 }
 
 func (n *ScriptCondition) Tick() core.NodeStatus {
-	n.loadExecutor()
+	if err := n.loadScript(); err != nil {
+		log.Printf("%v [%v]: %v", n.RegistrationName(), n.FullPath(), err)
+		return core.NodeStatus_FAILURE
+	}
 
 	result := n.executor(n.Config().Blackboard, n.Config().Enums)
 	if result {
@@ -29,21 +35,29 @@ func (n *ScriptCondition) Tick() core.NodeStatus {
 	return core.NodeStatus_FAILURE
 }
 
-func (n *ScriptCondition) loadExecutor() {
-	var script string
-	v, err := n.GetInput("code", script)
+// LoadExecutor parses the script when the tree is created. When the port [code] is remapped to
+// an entry of the blackboard, that might be written later, the script is parsed by Tick instead.
+func (n *ScriptCondition) LoadExecutor() error {
+	if _, ok := core.IsBlackboardPointer(n.GetRawPortValue("code")); ok {
+		return nil
+	}
+	return n.loadScript()
+}
+
+// loadScript parses the script read from the port [code], unless it didn't change
+func (n *ScriptCondition) loadScript() error {
+	script, err := core.GetInput[string](n, "code")
 	if err != nil {
-		panic("Missing port [code] in ScriptCondition")
+		return fmt.Errorf("missing port [code] in ScriptCondition: %v", err)
 	}
-	script = v.(string)
-	if script == n.script {
-		return
+	if script == n.script && n.executor != nil {
+		return nil
 	}
 	executor, err := core.ParseScript(script)
 	if err != nil {
-		panic(err)
-	} else {
-		n.executor = executor
-		n.script = script
+		return err
 	}
+	n.executor = executor
+	n.script = script
+	return nil
 }
@@ -1,6 +1,10 @@
 package actions
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"log"
+)
 
 func init() {
 	core.SetPorts(&ScriptNode{}, core.InputPort("code", "Piece of code that can be parsed"))
@@ -15,34 +19,40 @@ type ScriptNode struct {
 func NewScriptNode(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
 	n := &ScriptNode{SyncActionNode: core.NewSyncActionNode(name, cfg)}
 	n.SetRegistrationID("ScriptNode")
-	n.loadExecutor()
 	return n
 }
 func (n *ScriptNode) Tick() core.NodeStatus {
-	n.loadExecutor()
+	if err := n.loadScript(); err != nil {
+		log.Printf("%v [%v]: %v", n.RegistrationName(), n.FullPath(), err)
+		return core.NodeStatus_FAILURE
+	}
+	n.executor(n.Config().Blackboard, n.Config().Enums)
+	return core.NodeStatus_SUCCESS
+}
 
-	result := n.executor(n.Config().Blackboard, n.Config().Enums)
-	if result {
-		return core.NodeStatus_SUCCESS
+// LoadExecutor parses the script when the tree is created. When the port [code] is remapped to
+// an entry of the blackboard, that might be written later, the script is parsed by Tick instead.
+func (n *ScriptNode) LoadExecutor() error {
+	if _, ok := core.IsBlackboardPointer(n.GetRawPortValue("code")); ok {
+		return nil
 	}
-	return core.NodeStatus_FAILURE
+	return n.loadScript()
 }
 
-func (n *ScriptNode) loadExecutor() {
-	var script string
-	v, err := n.GetInput("code", script)
+// loadScript parses the script read from the port [code], unless it didn't change
+func (n *ScriptNode) loadScript() error {
+	script, err := core.GetInput[string](n, "code")
 	if err != nil {
-		panic("Missing port [code] in Script")
+		return fmt.Errorf("missing port [code] in Script: %v", err)
 	}
-	script = v.(string)
-	if script == n.script {
-		return
+	if script == n.script && n.executor != nil {
+		return nil
 	}
 	executor, err := core.ParseScript(script)
 	if err != nil {
-		panic(err)
-	} else {
-		n.executor = executor
-		n.script = script
+		return err
 	}
+	n.executor = executor
+	n.script = script
+	return nil
 }
@@ -0,0 +1,54 @@
+package actions_test
+
+import (
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"testing"
+)
+
+func TestScriptFromBlackboard(t *testing.T) {
+	for _, id := range []string{"Script", "ScriptCondition"} {
+		f := core.NewBehaviorTreeFactory()
+		builtins.RegisterNodes(f)
+		// the entry [code] is written after the creation of the tree
+		tree, err := f.CreateTreeFromText(`<root BTCPP_format="4"><BehaviorTree ID="Main">
+			<` + id + ` code="{code}"/>
+		</BehaviorTree></root>`)
+		if err != nil {
+			t.Fatalf("%v: %v", id, err)
+		}
+		bb := tree.Subtrees[0].Blackboard
+		tests := []struct {
+			code string
+			// a Script returns SUCCESS whatever the value of its last expression
+			script, condition core.NodeStatus
+		}{
+			// not written yet
+			{"", core.NodeStatus_FAILURE, core.NodeStatus_FAILURE},
+			{"x:=1; x==1", core.NodeStatus_SUCCESS, core.NodeStatus_SUCCESS},
+			{"x:=2; x==1", core.NodeStatus_SUCCESS, core.NodeStatus_FAILURE},
+			// invalid script
+			{"x:=", core.NodeStatus_FAILURE, core.NodeStatus_FAILURE},
+		}
+		for _, tt := range tests {
+			if tt.code != "" {
+				bb.Set("code", tt.code)
+			}
+			want := tt.script
+			if id == "ScriptCondition" {
+				want = tt.condition
+			}
+			if status := tree.TickWhileRunning(); status != want {
+				t.Errorf("%v [%v]: status %v, want %v", id, tt.code, status, want)
+			}
+		}
+
+		// a static script is still parsed when the tree is created
+		_, err = f.CreateTreeFromText(`<root BTCPP_format="4"><BehaviorTree ID="Main">
+			<` + id + ` code="x:="/>
+		</BehaviorTree></root>`)
+		if err == nil {
+			t.Errorf("%v: no error for an invalid script", id)
+		}
+	}
+}
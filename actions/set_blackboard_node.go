@@ -17,12 +17,10 @@ func NewSetBlackboardNode(name string, cfg *core.NodeConfig, args ...interface{}
 	return n
 }
 
-func (n *SetBlackboardNode) tick() core.NodeStatus {
-	var outputKey string
-	if v, err := n.GetInput("output_key", outputKey); err != nil {
-		panic("missing port [output_key]")
-	} else {
-		outputKey = v.(string)
+func (n *SetBlackboardNode) Tick() core.NodeStatus {
+	outputKey, err := core.GetInput[string](n, "output_key")
+	if err != nil {
+		panic(err)
 	}
 
 	valueStr := n.Config().InputPorts["value"]
@@ -35,7 +33,7 @@ func (n *SetBlackboardNode) tick() core.NodeStatus {
 			panic("Can't find the port referred by [value]")
 		}
 		if dstEntry == nil {
-			n.Config().Blackboard.CreateEntry(outputKey, srcEntry.info)
+			n.Config().Blackboard.CreateEntry(outputKey, srcEntry.Info)
 			dstEntry = n.Config().Blackboard.GetEntry(outputKey)
 		}
 		dstEntry.Value = srcEntry.Value
@@ -1,6 +1,7 @@
 package actions
 
 import (
+	"fmt"
 	"github.com/gorustyt/go-behavior/core"
 	"sync"
 	"time"
@@ -28,12 +29,11 @@ func NewSleepNode(name string, cfg *core.NodeConfig, args ...interface{}) core.I
 }
 
 func (n *SleepNode) OnStart() core.NodeStatus {
-	var m int
-	v, err := n.GetInput("msec", &m)
+	m, err := core.GetInput[int](n, "msec")
 	if err != nil {
-		panic("Missing parameter [msec] in SleepNode")
+		panic(fmt.Sprintf("Missing parameter [msec] in SleepNode: %v", err))
 	}
-	msec := time.Duration(v.(int))
+	msec := time.Duration(m) * time.Millisecond
 	if msec <= 0 {
 		return core.NodeStatus_SUCCESS
 	}
@@ -19,12 +19,10 @@ func NewUnsetBlackboardNode(name string, cfg *core.NodeConfig, args ...interface
 }
 
 func (n *UnsetBlackboardNode) Tick() core.NodeStatus {
-	var key string
-	v, err := n.GetInput("key", &key)
+	key, err := core.GetInput[string](n, "key")
 	if err != nil {
-		panic("missing input port [key]")
+		panic(err)
 	}
-	key = v.(string)
 	n.Config().Blackboard.Unset(key)
 	return core.NodeStatus_SUCCESS
 }
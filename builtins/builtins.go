@@ -0,0 +1,73 @@
+// Package builtins registers the nodes of the packages actions, controls and decorators, that
+// core can't import, into a BehaviorTreeFactory.
+package builtins
+
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/actions"
+	"github.com/gorustyt/go-behavior/controls"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/decorators"
+	"reflect"
+)
+
+// register registers the node of type T created by cons with args; the manifest is derived from T,
+// so cons isn't called until the node is instantiated
+func register[T core.ITreeNode](f *core.BehaviorTreeFactory, id string, cons core.NodeBuilderFn, args []any, opts ...core.RegisterOption) {
+	err := core.RegisterBuiltin(f, id, func(name string, cfg *core.NodeConfig) T {
+		return cons(name, cfg, args...).(T)
+	}, opts...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RegisterNodes registers the built-in nodes (Sequence, Fallback, SubTree, Script, ...) into the factory.
+// It panics if one of their IDs is already registered.
+func RegisterNodes(f *core.BehaviorTreeFactory) {
+	register[*controls.FallbackNode](f, "Fallback", controls.NewFallbackNode, nil)
+	register[*controls.FallbackNode](f, "AsyncFallback", controls.NewFallbackNode, []any{true})
+	register[*controls.SequenceNode](f, "Sequence", controls.NewSequenceNode, nil)
+	register[*controls.SequenceNode](f, "AsyncSequence", controls.NewSequenceNode, []any{true})
+	register[*controls.SequenceWithMemory](f, "SequenceWithMemory", controls.NewSequenceWithMemory, nil)
+	register[*controls.SequenceWithMemory](f, "SequenceStar", controls.NewSequenceWithMemory, nil)
+	register[*controls.ParallelNode](f, "Parallel", controls.NewParallelNode, nil)
+	register[*controls.ParallelAllNode](f, "ParallelAll", controls.NewParallelAllNode, nil)
+	register[*controls.ReactiveSequence](f, "ReactiveSequence", controls.NewReactiveSequence, nil)
+	register[*controls.ReactiveFallback](f, "ReactiveFallback", controls.NewReactiveFallback, nil)
+	register[*controls.IfThenElseNode](f, "IfThenElse", controls.NewIfThenElseNode, nil)
+	register[*controls.WhileDoElseNode](f, "WhileDoElse", controls.NewWhileDoElseNode, nil)
+	register[*controls.ManualSelectorNode](f, "ManualSelector", controls.NewManualSelectorNode, nil)
+	for numCases := 2; numCases <= 6; numCases++ {
+		register[*controls.SwitchNode](f, fmt.Sprintf("Switch%d", numCases), controls.NewSwitchNode, []any{numCases},
+			core.WithPorts(controls.SwitchPorts(numCases)...))
+	}
+	register[*controls.SwitchNode](f, "SwitchN", controls.NewSwitchNode, []any{controls.SwitchN},
+		core.WithPorts(controls.SwitchPorts(0)...), core.WithDynamicPort(controls.SwitchCasePort))
+
+	register[*decorators.InverterNode](f, "Inverter", decorators.NewInverterNode, nil)
+	register[*decorators.RetryNode](f, "RetryUntilSuccessful", decorators.NewRetryNode, nil)
+	register[*decorators.KeepRunningUntilFailureNode](f, "KeepRunningUntilFailure", decorators.NewKeepRunningUntilFailureNode, nil)
+	register[*decorators.RepeatNode](f, "Repeat", decorators.NewRepeatNode, nil)
+	register[*decorators.TimeoutNode](f, "Timeout", decorators.NewTimeoutNode, nil)
+	register[*decorators.DelayNode](f, "Delay", decorators.NewDelayNode, nil)
+	register[*decorators.RunOnceNode](f, "RunOnce", decorators.NewRunOnceNode, nil)
+	register[*decorators.ForceSuccessNode](f, "ForceSuccess", decorators.NewForceSuccessNode, nil)
+	register[*decorators.ForceFailureNode](f, "ForceFailure", decorators.NewForceFailureNode, nil)
+	register[*decorators.SubTreeNode](f, "SubTree", decorators.NewSubTreeNode, nil, core.WithNodeType(core.NodeType_SUBTREE))
+	register[*decorators.PreconditionNode](f, "Precondition", decorators.NewPreconditionNode, nil)
+	register[*decorators.EntryUpdatedNode](f, "SkipUnlessUpdated", decorators.NewEntryUpdatedNode, []any{core.NodeStatus_SKIPPED})
+	register[*decorators.EntryUpdatedNode](f, "WaitValueUpdate", decorators.NewEntryUpdatedNode, []any{core.NodeStatus_RUNNING})
+	register[*decorators.LoopNode](f, "LoopInt", decorators.NewLoopNode, []any{reflect.Int})
+	register[*decorators.LoopNode](f, "LoopBool", decorators.NewLoopNode, []any{reflect.Bool})
+	register[*decorators.LoopNode](f, "LoopDouble", decorators.NewLoopNode, []any{reflect.Float64})
+	register[*decorators.LoopNode](f, "LoopString", decorators.NewLoopNode, []any{reflect.String})
+
+	register[*actions.AlwaysSuccessNode](f, "AlwaysSuccess", actions.NewAlwaysSuccessNode, nil)
+	register[*actions.AlwaysFailureNode](f, "AlwaysFailure", actions.NewAlwaysFailureNode, nil)
+	register[*actions.ScriptNode](f, "Script", actions.NewScriptNode, nil)
+	register[*actions.ScriptCondition](f, "ScriptCondition", actions.NewScriptCondition, nil)
+	register[*actions.SetBlackboardNode](f, "SetBlackboard", actions.NewSetBlackboardNode, nil)
+	register[*actions.SleepNode](f, "Sleep", actions.NewSleepNode, nil)
+	register[*actions.UnsetBlackboardNode](f, "UnsetBlackboard", actions.NewUnsetBlackboardNode, nil)
+}
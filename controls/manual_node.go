@@ -1,29 +1,97 @@
 package controls
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+)
 
 const (
 	REPEAT_LAST_SELECTION = "repeat_last_selection"
 )
 
-func init() {
-	core.SetPorts(&ManualSelectorNode{}, core.InputPortWithDefaultValue(REPEAT_LAST_SELECTION, false,
-		"If true, execute again the same child that was selected the last time"))
-}
-
-type NumericalStatus int
-
+// pseudo-choices of a SelectionProvider: the node returns the status without executing any child
 const (
 	NUM_SUCCESS = 253
 	NUM_FAILURE = 254
 	NUM_RUNNING = 255
 )
 
+// ManualSelectorNode asks a SelectionProvider which child to execute, or which status to return.
+// The provider is the first argument of the constructor, DefaultSelectionProvider if missing.
 type ManualSelectorNode struct {
+	*core.ControlNode
+	provider                 SelectionProvider
 	running_child_idx_       int
 	previously_executed_idx_ int
+
+	RepeatLastSelection bool `bt:"in,repeat_last_selection,default=false,desc=If true, execute again the same child that was selected the last time"`
+}
+
+func NewManualSelectorNode(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	n := &ManualSelectorNode{
+		ControlNode:              core.NewControlNode(name, cfg),
+		provider:                 DefaultSelectionProvider,
+		running_child_idx_:       -1,
+		previously_executed_idx_: -1,
+	}
+	if len(args) > 0 {
+		n.provider = args[0].(SelectionProvider)
+	}
+	n.SetRegistrationID("ManualSelector")
+	return n
 }
 
 func (n *ManualSelectorNode) Halt() {
+	if n.running_child_idx_ >= 0 {
+		n.HaltChild(n.running_child_idx_)
+	}
+	n.running_child_idx_ = -1
+	n.ControlNode.Halt()
+}
+
+func (n *ManualSelectorNode) Tick() core.NodeStatus {
+	idx := n.running_child_idx_
+	if idx < 0 {
+		if n.RepeatLastSelection && n.previously_executed_idx_ >= 0 {
+			idx = n.previously_executed_idx_
+		} else {
+			n.SetStatus(core.NodeStatus_RUNNING)
+			idx = n.selectChild()
+			switch idx {
+			case NUM_SUCCESS:
+				return core.NodeStatus_SUCCESS
+			case NUM_FAILURE:
+				return core.NodeStatus_FAILURE
+			case NUM_RUNNING:
+				return core.NodeStatus_RUNNING
+			}
+			n.previously_executed_idx_ = idx
+		}
+	}
+
+	status := n.Children[idx].ExecuteTick()
+	if status == core.NodeStatus_RUNNING {
+		n.running_child_idx_ = idx
+	} else {
+		n.running_child_idx_ = -1
+	}
+	return status
+}
 
+// selectChild asks the provider for a child or a pseudo-choice, and checks the answer
+func (n *ManualSelectorNode) selectChild() int {
+	names := make([]string, len(n.Children))
+	for i, child := range n.Children {
+		names[i] = child.Name()
+	}
+	idx, err := n.provider.SelectChild(n.Name(), names)
+	if err != nil {
+		panic(fmt.Sprintf("ManualSelectorNode [%v]: %v", n.Name(), err))
+	}
+	switch {
+	case idx == NUM_SUCCESS, idx == NUM_FAILURE, idx == NUM_RUNNING:
+	case idx < 0 || idx >= len(n.Children):
+		panic(fmt.Sprintf("ManualSelectorNode [%v]: invalid selection [%v]", n.Name(), idx))
+	}
+	return idx
 }
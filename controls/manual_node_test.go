@@ -0,0 +1,134 @@
+package controls
+
+import (
+	"github.com/gorustyt/go-behavior/core"
+	"strings"
+	"testing"
+)
+
+// runningOnce returns RUNNING the first time it's ticked, then SUCCESS
+type runningOnce struct {
+	*core.ActionNodeBase
+	ticks *[]string
+	runs  map[string]int
+}
+
+func (n *runningOnce) Tick() core.NodeStatus {
+	*n.ticks = append(*n.ticks, n.Name())
+	n.runs[n.Name()]++
+	if n.runs[n.Name()] == 1 {
+		return core.NodeStatus_RUNNING
+	}
+	return core.NodeStatus_SUCCESS
+}
+
+func newManualTestTree(t *testing.T, choices <-chan int, ticks *[]string) *core.Tree {
+	f := core.NewBehaviorTreeFactory()
+	f.RegisterNodeType("ScriptedSelector", NewManualSelectorNode, SelectionProvider(NewScriptedSelectionProvider(choices)))
+	runs := map[string]int{}
+	f.RegisterNodeType("RunningOnce", func(name string, cfg *core.NodeConfig, args ...any) core.ITreeNode {
+		return &runningOnce{ActionNodeBase: core.NewActionNodeBase(name, cfg), ticks: ticks, runs: runs}
+	})
+	f.RegisterSimpleAction("Fail", func(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
+		*ticks = append(*ticks, node.Name())
+		return core.NodeStatus_FAILURE
+	})
+	tree, err := f.CreateTreeFromText(`
+<root BTCPP_format="4">
+  <BehaviorTree ID="Main">
+    <ScriptedSelector repeat_last_selection="{repeat}">
+      <RunningOnce name="a"/>
+      <Fail name="f"/>
+      <RunningOnce name="b"/>
+    </ScriptedSelector>
+  </BehaviorTree>
+</root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Subtrees[0].Blackboard.Set("repeat", false)
+	return tree
+}
+
+func TestManualSelectorChildSelection(t *testing.T) {
+	choices := make(chan int, 10)
+	var ticks []string
+	tree := newManualTestTree(t, choices, &ticks)
+
+	choices <- 1
+	if s := tree.TickOnce(); s != core.NodeStatus_FAILURE {
+		t.Fatal(s)
+	}
+	choices <- 0
+	if s := tree.TickOnce(); s != core.NodeStatus_RUNNING {
+		t.Fatal(s)
+	}
+	// the running child is ticked again without asking the provider
+	if s := tree.TickOnce(); s != core.NodeStatus_SUCCESS {
+		t.Fatal(s)
+	}
+	if got := strings.Join(ticks, ","); got != "f,a,a" {
+		t.Fatal(got)
+	}
+}
+
+func TestManualSelectorRepeatLastSelection(t *testing.T) {
+	choices := make(chan int, 10)
+	var ticks []string
+	tree := newManualTestTree(t, choices, &ticks)
+
+	choices <- 2
+	if s := tree.TickOnce(); s != core.NodeStatus_RUNNING {
+		t.Fatal(s)
+	}
+	if s := tree.TickOnce(); s != core.NodeStatus_SUCCESS {
+		t.Fatal(s)
+	}
+	tree.Subtrees[0].Blackboard.Set("repeat", true)
+	// the last selection is executed again, the provider isn't asked
+	if s := tree.TickOnce(); s != core.NodeStatus_SUCCESS || len(choices) != 0 {
+		t.Fatal(s)
+	}
+	if got := strings.Join(ticks, ","); got != "b,b,b" {
+		t.Fatal(got)
+	}
+}
+
+func TestManualSelectorPseudoChoices(t *testing.T) {
+	choices := make(chan int, 10)
+	var ticks []string
+	tree := newManualTestTree(t, choices, &ticks)
+
+	for _, c := range []struct {
+		choice int
+		want   core.NodeStatus
+	}{
+		{NUM_SUCCESS, core.NodeStatus_SUCCESS},
+		{NUM_FAILURE, core.NodeStatus_FAILURE},
+		{NUM_RUNNING, core.NodeStatus_RUNNING},
+		// after NUM_RUNNING the provider is asked again
+		{NUM_SUCCESS, core.NodeStatus_SUCCESS},
+	} {
+		choices <- c.choice
+		if s := tree.TickOnce(); s != c.want {
+			t.Fatalf("choice %v: got %v, want %v", c.choice, s, c.want)
+		}
+	}
+	if len(ticks) != 0 {
+		t.Fatal(ticks)
+	}
+}
+
+func TestManualSelectorInvalidSelection(t *testing.T) {
+	choices := make(chan int, 1)
+	var ticks []string
+	tree := newManualTestTree(t, choices, &ticks)
+
+	choices <- 3
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("invalid selection accepted")
+		}
+	}()
+	tree.TickOnce()
+}
@@ -25,10 +25,8 @@ func NewParallelAllNode(name string, cfg *core.NodeConfig, args ...interface{})
 		failure_threshold_: 1,
 	}
 }
-func (n *ParallelAllNode) tick() core.NodeStatus {
-	maxFailures := 0
-	v, err := n.GetInput("max_failures", &maxFailures)
-	maxFailures = v.(int)
+func (n *ParallelAllNode) Tick() core.NodeStatus {
+	maxFailures, err := core.GetInput[int](n, "max_failures")
 	if err != nil {
 		panic(fmt.Sprintf("Missing parameter [max_failures] in ParallelNode%v", err))
 	}
@@ -9,7 +9,7 @@ func init() {
 	core.SetPorts(&ParallelNode{}, core.InputPortWithDefaultValue(THRESHOLD_SUCCESS, -1,
 		"number of children that need to succeed to trigger a SUCCESS"))
 
-	core.SetPorts(core.InputPortWithDefaultValue(THRESHOLD_FAILURE, 1,
+	core.SetPorts(&ParallelNode{}, core.InputPortWithDefaultValue(THRESHOLD_FAILURE, 1,
 		"number of children that need to fail to trigger a FAILURE"))
 }
 
@@ -59,16 +59,16 @@ func NewParallelNode(name string, cfg *core.NodeConfig, args ...interface{}) cor
 
 func (n *ParallelNode) Tick() core.NodeStatus {
 	if n.readParameterFromPorts {
-		v, err := n.GetInput(THRESHOLD_SUCCESS, 0)
-		n.success_threshold_ = v.(int)
+		v, err := core.GetInput[int](n, THRESHOLD_SUCCESS)
 		if err != nil {
 			panic(fmt.Sprintf("Missing parameter [%v] in ParallelNode err:%v", THRESHOLD_SUCCESS, err))
 		}
-		v, err = n.GetInput(THRESHOLD_FAILURE, 0)
-		n.failure_threshold_ = v.(int)
+		n.success_threshold_ = v
+		v, err = core.GetInput[int](n, THRESHOLD_FAILURE)
 		if err != nil {
 			panic(fmt.Sprintf("Missing parameter [%v] in ParallelNode :%v", THRESHOLD_FAILURE, err))
 		}
+		n.failure_threshold_ = v
 	}
 
 	childrenCount := len(n.Children)
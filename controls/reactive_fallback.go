@@ -69,5 +69,5 @@ func (n *ReactiveFallback) Tick() core.NodeStatus {
 
 func (n *ReactiveFallback) Halt() {
 	n.runningChild = -1
-	n.Halt()
+	n.ControlNode.Halt()
 }
@@ -82,5 +82,5 @@ func (n *ReactiveSequence) Tick() core.NodeStatus {
 
 func (n *ReactiveSequence) Halt() {
 	n.runningChild = -1
-	n.Halt()
+	n.ControlNode.Halt()
 }
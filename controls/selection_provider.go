@@ -0,0 +1,93 @@
+package controls
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SelectionProvider chooses the child executed by a ManualSelectorNode
+type SelectionProvider interface {
+	// SelectChild returns the index of one of the children, or NUM_SUCCESS, NUM_FAILURE, NUM_RUNNING
+	SelectChild(title string, children []string) (int, error)
+}
+
+// DefaultSelectionProvider is used by the ManualSelectorNodes created without a provider
+var DefaultSelectionProvider SelectionProvider = NewTerminalSelectionProvider(os.Stdin, os.Stdout)
+
+// TerminalSelectionProvider shows a menu with ANSI escape codes and reads the number of the choice
+type TerminalSelectionProvider struct {
+	mutex  sync.Mutex
+	reader *bufio.Reader
+	writer io.Writer
+}
+
+func NewTerminalSelectionProvider(in io.Reader, out io.Writer) *TerminalSelectionProvider {
+	return &TerminalSelectionProvider{reader: bufio.NewReader(in), writer: out}
+}
+
+const (
+	ansiClear = "\x1b[2J\x1b[H"
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+)
+
+func (p *TerminalSelectionProvider) SelectChild(title string, children []string) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	// the pseudo-choices follow the children
+	pseudo := []struct {
+		label string
+		value int
+	}{{"SUCCESS", NUM_SUCCESS}, {"FAILURE", NUM_FAILURE}, {"RUNNING", NUM_RUNNING}}
+	for {
+		fmt.Fprintf(p.writer, "%vSelect a child of %v%v%v\n\n", ansiClear, ansiBold, title, ansiReset)
+		for i, child := range children {
+			fmt.Fprintf(p.writer, "  %v%d%v) %v\n", ansiBold, i+1, ansiReset, child)
+		}
+		for i, v := range pseudo {
+			fmt.Fprintf(p.writer, "  %v%d%v) %v%v%v\n", ansiBold, len(children)+i+1, ansiReset, ansiDim, v.label, ansiReset)
+		}
+		fmt.Fprint(p.writer, "\n> ")
+
+		line, err := p.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return 0, err
+		}
+		choice, convErr := strconv.Atoi(strings.TrimSpace(line))
+		switch {
+		case convErr == nil && choice >= 1 && choice <= len(children):
+			return choice - 1, nil
+		case convErr == nil && choice > len(children) && choice <= len(children)+len(pseudo):
+			return pseudo[choice-len(children)-1].value, nil
+		}
+		if err == io.EOF {
+			return 0, fmt.Errorf("invalid selection [%v]", strings.TrimSpace(line))
+		}
+	}
+}
+
+// ScriptedSelectionProvider returns the choices received from a channel, e.g. in tests
+type ScriptedSelectionProvider struct {
+	choices <-chan int
+}
+
+func NewScriptedSelectionProvider(choices <-chan int) *ScriptedSelectionProvider {
+	return &ScriptedSelectionProvider{choices: choices}
+}
+
+var errNoMoreChoices = errors.New("the channel of the choices is closed")
+
+func (p *ScriptedSelectionProvider) SelectChild(title string, children []string) (int, error) {
+	choice, ok := <-p.choices
+	if !ok {
+		return 0, errNoMoreChoices
+	}
+	return choice, nil
+}
@@ -3,9 +3,17 @@ package controls
 import (
 	"fmt"
 	"github.com/gorustyt/go-behavior/core"
+	"math"
 	"strconv"
 )
 
+// SwitchN is the number of cases of a SwitchNode inferred from its children: all but the last one,
+// that is the default.
+const SwitchN = -1
+
+// SwitchNode executes the child of the first case_N port equal to the port "variable", or the last
+// child (default) if none matches. The values are compared as strings, as integers (the names of
+// the scripting enums are replaced by their values) and as real numbers.
 type SwitchNode struct {
 	*core.ControlNode
 	runningChild int
@@ -15,18 +23,23 @@ type SwitchNode struct {
 func NewSwitchNode(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
 	var numCases int
 	if len(args) > 0 {
-		numCases, _ = strconv.Atoi(args[0].(string))
+		switch v := args[0].(type) {
+		case int:
+			numCases = v
+		case string:
+			numCases, _ = strconv.Atoi(v)
+		}
 	}
 	n := &SwitchNode{
 		numCases:     numCases,
 		ControlNode:  core.NewControlNode(name, cfg),
 		runningChild: -1,
 	}
-	core.SetPorts(&SwitchNode{}, core.InputPortWithDefaultValue("variable", ""))
-	for i := 0; i < numCases; i++ {
-		core.SetPorts(&SwitchNode{}, core.InputPortWithDefaultValue(fmt.Sprintf("case_%d", i+1), ""))
+	if numCases == SwitchN {
+		n.SetRegistrationID("SwitchN")
+	} else {
+		n.SetRegistrationID(fmt.Sprintf("Switch%d", numCases))
 	}
-	n.SetRegistrationID("Switch")
 	return n
 }
 func (n *SwitchNode) Halt() {
@@ -34,39 +47,63 @@ func (n *SwitchNode) Halt() {
 	n.ControlNode.Halt()
 }
 
-func (n *SwitchNode) ProvidedPorts() map[string]*core.PortInfo {
+// GetProvidedPorts returns the port "variable" and one port "case_N" for each case
+func (n *SwitchNode) GetProvidedPorts() map[string]*core.PortInfo {
 	res := map[string]*core.PortInfo{}
-	v := core.InputPortWithDefaultValue("variable", "")
-	res[v.Name] = v
-	for i := 0; i < n.numCases; i++ {
-		caseStr := fmt.Sprintf("case_%d", i+1)
-		res[v.Name] = core.InputPortWithDefaultValue(caseStr, "")
+	for _, p := range SwitchPorts(n.numCases) {
+		res[p.Name] = p
+	}
+	return res
+}
+
+// SwitchPorts returns the port "variable" and the ports case_1 ... case_numCases
+func SwitchPorts(numCases int) []*core.PortInfo {
+	res := []*core.PortInfo{core.InputPort("variable")}
+	for i := 0; i < numCases; i++ {
+		res = append(res, core.InputPort(fmt.Sprintf("case_%d", i+1)))
 	}
 	return res
 }
+
+// SwitchCasePort is the DynamicPort of the manifest of SwitchN: it accepts any port case_N
+func SwitchCasePort(name string) *core.PortInfo {
+	var i int
+	if _, err := fmt.Sscanf(name, "case_%d", &i); err != nil || i < 1 || name != fmt.Sprintf("case_%d", i) {
+		return nil
+	}
+	return core.InputPort(name)
+}
+
+// ValidateChildren checks, when the tree is created, that there is a child for each case plus the default
+func (n *SwitchNode) ValidateChildren() error {
+	if n.numCases == SwitchN {
+		if len(n.Children) == 0 {
+			return fmt.Errorf("SwitchN requires at least 1 child (default)")
+		}
+	} else if len(n.Children) != n.numCases+1 {
+		return fmt.Errorf("wrong number of children in Switch%d: %d, must be %d (num_cases + default)",
+			n.numCases, len(n.Children), n.numCases+1)
+	}
+	return nil
+}
+
 func (n *SwitchNode) Tick() core.NodeStatus {
-	if len(n.Children) != n.numCases+1 {
+	numCases := n.numCases
+	if numCases == SwitchN {
+		numCases = len(n.Children) - 1
+	}
+	// checked by ValidateChildren, unless the children are added after the creation of the tree
+	if numCases < 0 || len(n.Children) != numCases+1 {
 		panic("Wrong number of children in SwitchNode; must be (num_cases + default)")
 	}
 
-	matchIndex := n.numCases // default index;
-	var (
-		variable int
-		value    int
-	)
-	v, err := n.GetInput("variable", &variable)
-	if err == nil {
-		variable = v.(int)
+	matchIndex := numCases // default index;
+	// no variable? jump to default
+	if variable, err := core.GetInput[string](n, "variable"); err == nil {
 		// check each case until you find a match
-		for index := 0; index < n.numCases; index++ {
-			caseKey := fmt.Sprintf("case_%d", int(index+1))
-			v1, err := n.GetInput(caseKey, &value)
-			if err != nil {
-				panic(err)
-			}
-			value = v1.(int)
-			if err == nil && variable == value {
-
+		for index := 0; index < numCases; index++ {
+			value, err := core.GetInput[string](n, fmt.Sprintf("case_%d", index+1))
+			if err == nil && checkStringEquality(variable, value, n.Config().Enums) {
 				matchIndex = index
 				break
 			}
@@ -93,3 +130,26 @@ func (n *SwitchNode) Tick() core.NodeStatus {
 	}
 	return ret
 }
+
+// checkStringEquality compares two values of the ports as strings, then as integers, replacing the
+// names of the enums with their values, and finally as real numbers.
+func checkStringEquality(v1, v2 string, enums map[string]int) bool {
+	if v1 == v2 {
+		return true
+	}
+	toInt := func(str string) (int, bool) {
+		if v, ok := enums[str]; ok {
+			return v, true
+		}
+		v, err := strconv.Atoi(str)
+		return v, err == nil
+	}
+	if i1, ok := toInt(v1); ok {
+		if i2, ok := toInt(v2); ok && i1 == i2 {
+			return true
+		}
+	}
+	f1, err1 := strconv.ParseFloat(v1, 64)
+	f2, err2 := strconv.ParseFloat(v2, 64)
+	return err1 == nil && err2 == nil && math.Abs(f1-f2) <= 1e-6
+}
@@ -0,0 +1,96 @@
+package controls_test
+
+import (
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"strings"
+	"testing"
+)
+
+func newSwitchTestFactory() *core.BehaviorTreeFactory {
+	f := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(f)
+	f.RegisterScriptingEnum("RED", 3)
+	return f
+}
+
+// switchTestTree returns a tree whose children of the switch write their index to the entry [picked]
+func switchTestTree(switchID, cases string, numChildren int) string {
+	children := ""
+	for i := 1; i <= numChildren; i++ {
+		children += `<Script code="picked:=` + string(rune('0'+i)) + `"/>`
+	}
+	return `<root BTCPP_format="4"><BehaviorTree ID="Main">
+		<` + switchID + ` variable="{v}" ` + cases + `>` + children + `</` + switchID + `>
+	</BehaviorTree></root>`
+}
+
+func TestSwitchCaseEquality(t *testing.T) {
+	tree, err := newSwitchTestFactory().CreateTreeFromText(switchTestTree("Switch4",
+		`case_1="text" case_2="RED" case_3="2" case_4="2.5"`, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		variable string
+		picked   int
+	}{
+		{"text", 1},
+		// enums, by name or value
+		{"RED", 2},
+		{"3", 2},
+		// integers
+		{"02", 3},
+		{"+2", 3},
+		// real numbers
+		{"2.0", 3},
+		{"2.50", 4},
+		{"2.5000001", 4},
+		{"2.6", 5},
+		{"Text", 5},
+		{"", 5},
+	}
+	bb := tree.Subtrees[0].Blackboard
+	for _, tt := range tests {
+		bb.Set("v", tt.variable)
+		if status := tree.TickWhileRunning(); status != core.NodeStatus_SUCCESS {
+			t.Fatalf("[%v]: status %v, want SUCCESS", tt.variable, status)
+		}
+		if picked := core.BBGetOr(bb, "picked", 0); picked != tt.picked {
+			t.Errorf("[%v]: child %v executed, want %v", tt.variable, picked, tt.picked)
+		}
+	}
+}
+
+func TestSwitchN(t *testing.T) {
+	tree, err := newSwitchTestFactory().CreateTreeFromText(switchTestTree("SwitchN", `case_1="a" case_2="b"`, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := tree.Subtrees[0].Blackboard
+	for variable, want := range map[string]int{"a": 1, "b": 2, "c": 3} {
+		bb.Set("v", variable)
+		tree.TickWhileRunning()
+		if picked := core.BBGetOr(bb, "picked", 0); picked != want {
+			t.Errorf("[%v]: child %v executed, want %v", variable, picked, want)
+		}
+	}
+
+	// a case without child is rejected by the manifest, or when the tree is created
+	for _, tt := range []struct {
+		switchID, cases string
+		numChildren     int
+	}{
+		{"Switch2", `case_1="a" case_2="b"`, 2},
+		{"Switch2", `case_1="a" case_2="b"`, 4},
+		{"Switch3", `case_1="a" case_2="b" case_3="c"`, 3},
+		{"SwitchN", `case_1="a"`, 0},
+	} {
+		_, err := newSwitchTestFactory().CreateTreeFromText(switchTestTree(tt.switchID, tt.cases, tt.numChildren))
+		if err == nil {
+			t.Errorf("%v with %d children: no error", tt.switchID, tt.numChildren)
+		} else if tt.numChildren > 0 && !strings.Contains(err.Error(), "children") {
+			t.Errorf("%v with %d children: %v", tt.switchID, tt.numChildren, err)
+		}
+	}
+}
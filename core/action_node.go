@@ -1,9 +1,13 @@
 package core
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type ActionNodeBase struct {
@@ -114,55 +118,261 @@ func (n *StatefulActionNode) Halt() {
 	n.ResetStatus() // might be redundant
 }
 
+// ThreadedActionError is the panic raised by the ExecuteTick of a ThreadedAction,
+// on the tick that follows a panic of its Tick in the worker goroutine.
+type ThreadedActionError struct {
+	// full path of the node
+	Path  string
+	Value any
+	Stack []byte
+}
+
+func (e *ThreadedActionError) Error() string {
+	return fmt.Sprintf("ThreadedAction [%v]: panic in Tick: %v", e.Path, e.Value)
+}
+
+func (e *ThreadedActionError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// DefaultThreadedActionHaltTimeout is the time Halt waits for the worker goroutine to return
+const DefaultThreadedActionHaltTimeout = 5 * time.Second
+
+// ThreadedAction executes Tick in a separate goroutine; the node is RUNNING until Tick returns.
+// Tick should check Context (or IsHaltRequested) regularly and return as soon as it is canceled.
 type ThreadedAction struct {
 	*ActionNodeBase
-	//std::exception_ptr exptr_;
-	halt_requested_ atomic.Bool
-	//std::future<void> thread_handle_;
-	mutex_ sync.Mutex
-	wg     sync.WaitGroup
+	mutex_      sync.Mutex
+	run         *threadedRun
+	err         *ThreadedActionError
+	haltTimeout time.Duration
+}
+
+// threadedRun is a single execution of Tick
+type threadedRun struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	// set by Halt: the result of Tick is discarded
+	halted bool
 }
 
 func NewThreadedAction(name string, config *NodeConfig) *ThreadedAction {
-	return &ThreadedAction{ActionNodeBase: NewActionNodeBase(name, config)}
+	return &ThreadedAction{ActionNodeBase: NewActionNodeBase(name, config), haltTimeout: DefaultThreadedActionHaltTimeout}
+}
+
+// SetHaltTimeout changes the time Halt waits for Tick to return, after canceling its context
+func (n *ThreadedAction) SetHaltTimeout(timeout time.Duration) {
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
+	n.haltTimeout = timeout
+}
+
+// Context returns the context of the current execution of Tick, canceled by Halt
+// or when the context used to tick the tree is canceled.
+func (n *ThreadedAction) Context() context.Context {
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
+	if n.run == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx
+	}
+	return n.run.ctx
 }
 
 func (n *ThreadedAction) IsHaltRequested() bool {
-	return n.halt_requested_.Load()
+	return n.Context().Err() != nil
 }
 
-// This method spawn a new thread. Do NOT remove the "final" keyword.
 func (n *ThreadedAction) ExecuteTick() NodeStatus {
+	n.mutex_.Lock()
+	err := n.err
+	n.err = nil
+	n.mutex_.Unlock()
+	if err != nil {
+		panic(err)
+	}
 
-	//send signal to other thread.
-	// The other thread is in charge for changing the status
+	// the worker goroutine is in charge of changing the status
 	if n.Status() == NodeStatus_IDLE {
 		n.SetStatus(NodeStatus_RUNNING)
-		n.halt_requested_.Store(false)
-		n.wg.Add(1)
-		go func() {
-			status := n.Tick()
-			if !n.IsHaltRequested() {
-				n.SetStatus(status)
-			}
-			fmt.Printf("\nUncaught exception from tick(): [%v/%v]\n", n.registrationName(), n.Name())
-			// Set the exception pointer and the status atomically.
-			n.mutex_.Lock()
-			defer n.mutex_.Lock()
-			n.SetStatus(NodeStatus_IDLE)
-			n.EmitWakeUpSignal()
-			n.wg.Done()
-		}()
+		ctx, cancel := context.WithCancel(n.ActionNodeBase.Context())
+		run := &threadedRun{ctx: ctx, cancel: cancel, done: make(chan struct{})}
+		n.mutex_.Lock()
+		n.run = run
+		n.mutex_.Unlock()
+		go n.work(run)
 	}
 	return n.Status()
 }
 
+func (n *ThreadedAction) work(run *threadedRun) {
+	defer close(run.done)
+	status, err := n.safeTick()
+	run.cancel()
+
+	n.mutex_.Lock()
+	halted := run.halted
+	if !halted && err != nil {
+		// raised by the next ExecuteTick
+		n.err = err
+	}
+	n.mutex_.Unlock()
+	if halted {
+		return
+	}
+	// the status is changed without holding the lock, because it runs the subscribers;
+	// a concurrent Halt waits for run.done before resetting it
+	if err != nil {
+		n.ResetStatus()
+	} else {
+		n.SetStatus(status)
+	}
+	n.EmitWakeUpSignal()
+}
+
+// safeTick calls Tick, converting its panic to a ThreadedActionError
+func (n *ThreadedAction) safeTick() (status NodeStatus, err *ThreadedActionError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &ThreadedActionError{Path: n.FullPath(), Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return n.tick(), nil
+}
+
+// Halt cancels the context of Tick and waits for it to return, up to the halt timeout.
+// The result of Tick is discarded; if Tick doesn't return in time, the node is reset anyway.
 func (n *ThreadedAction) Halt() {
-	n.halt_requested_.Store(true)
-	n.wg.Wait()
+	n.mutex_.Lock()
+	run, timeout := n.run, n.haltTimeout
+	n.run = nil
+	if run != nil {
+		run.halted = true
+	}
+	n.mutex_.Unlock()
+	if run != nil {
+		run.cancel()
+		select {
+		case <-run.done:
+		case <-time.After(timeout):
+			log.Printf("ThreadedAction [%v]: Tick didn't return within %v after Halt", n.FullPath(), timeout)
+		}
+	}
 	n.ResetStatus() // might be redundant
 }
 
+// CoroActionNode runs the Tick of the node as a coroutine: the code is written as a straight
+// sequence of steps, calling Yield every time the node must return RUNNING to the tree.
+// The next tick resumes the code after the Yield.
+//
+// Tick runs on a dedicated goroutine, but never concurrently with the goroutine that ticks
+// the tree: the control is handed back and forth at each Yield. Halt unwinds the
+// coroutine, running its deferred functions, and waits for it to terminate.
 type CoroActionNode struct {
 	*ActionNodeBase
+	coro *coroutine
+}
+
+type coroResult struct {
+	status NodeStatus
+	panic  any
+}
+
+type coroutine struct {
+	resume chan struct{}
+	yield  chan coroResult
+	halt   chan struct{}
+	done   chan struct{}
+}
+
+// coroHalted is the panic used to unwind a halted coroutine
+type coroHalted struct{}
+
+func NewCoroActionNode(name string, config *NodeConfig) *CoroActionNode {
+	return &CoroActionNode{ActionNodeBase: NewActionNodeBase(name, config)}
+}
+
+func (n *CoroActionNode) ExecuteTick() NodeStatus {
+	return n.executeTick(n.resume)
+}
+
+// resume starts the coroutine, or continues it from the last Yield, and waits for
+// the next Yield or for the end of Tick.
+func (n *CoroActionNode) resume() NodeStatus {
+	if n.coro == nil {
+		c := &coroutine{
+			resume: make(chan struct{}),
+			yield:  make(chan coroResult),
+			halt:   make(chan struct{}),
+			done:   make(chan struct{}),
+		}
+		n.coro = c
+		go n.run(c)
+	}
+	c := n.coro
+	c.resume <- struct{}{}
+	res := <-c.yield
+	if res.panic != nil {
+		n.coro = nil
+		panic(res.panic)
+	}
+	if res.status != NodeStatus_RUNNING {
+		n.coro = nil
+	}
+	return res.status
+}
+
+func (n *CoroActionNode) run(c *coroutine) {
+	defer close(c.done)
+	var res coroResult
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				res.panic = r
+			}
+		}()
+		<-c.resume
+		res.status = n.tick()
+		if res.status == NodeStatus_RUNNING || res.status == NodeStatus_IDLE {
+			panic(fmt.Sprintf("CoroActionNode [%v]: Tick must return a completed status; use Yield to return RUNNING", n.Name()))
+		}
+	}()
+	if _, halted := res.panic.(coroHalted); halted {
+		return
+	}
+	select {
+	case c.yield <- res:
+	case <-c.halt:
+	}
+}
+
+// Yield returns RUNNING to the tree and suspends Tick until the next tick of the node.
+// It must be called only by the Tick of the node.
+func (n *CoroActionNode) Yield() {
+	c := n.coro
+	if c == nil {
+		panic(fmt.Sprintf("CoroActionNode [%v]: Yield called outside of Tick", n.Name()))
+	}
+	select {
+	case <-c.halt:
+		panic(coroHalted{})
+	case c.yield <- coroResult{status: NodeStatus_RUNNING}:
+	}
+	select {
+	case <-c.halt:
+		panic(coroHalted{})
+	case <-c.resume:
+	}
+}
+
+func (n *CoroActionNode) Halt() {
+	if c := n.coro; c != nil {
+		close(c.halt)
+		<-c.done
+		n.coro = nil
+	}
+	n.ResetStatus() // might be redundant
 }
@@ -0,0 +1,259 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// coroTestNode logs the steps of its Tick, yielding twice
+type coroTestNode struct {
+	*CoroActionNode
+	log   []string
+	panic any
+}
+
+func (n *coroTestNode) Tick() NodeStatus {
+	n.log = append(n.log, "start")
+	defer func() { n.log = append(n.log, "deferred") }()
+	n.Yield()
+	n.log = append(n.log, "resumed")
+	if n.panic != nil {
+		panic(n.panic)
+	}
+	n.Yield()
+	n.log = append(n.log, "end")
+	return NodeStatus_SUCCESS
+}
+
+// newActionTestNode registers the node type T and returns an instance created by the factory,
+// so that Tick and Halt are dispatched to T
+func newActionTestNode[T ITreeNode](t *testing.T, cons func(name string, cfg *NodeConfig) T) T {
+	t.Helper()
+	f := NewBehaviorTreeFactory()
+	if err := Register(f, "Test", cons); err != nil {
+		t.Fatal(err)
+	}
+	node, err := f.InstantiateTreeNode("node", "Test", &NodeConfig{Path: "node"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node.(T)
+}
+
+func newCoroTestNode(t *testing.T) *coroTestNode {
+	return newActionTestNode(t, func(name string, cfg *NodeConfig) *coroTestNode {
+		return &coroTestNode{CoroActionNode: NewCoroActionNode(name, cfg)}
+	})
+}
+
+func TestCoroActionNodeYield(t *testing.T) {
+	n := newCoroTestNode(t)
+	steps := []struct {
+		status NodeStatus
+		log    []string
+	}{
+		{NodeStatus_RUNNING, []string{"start"}},
+		{NodeStatus_RUNNING, []string{"start", "resumed"}},
+		{NodeStatus_SUCCESS, []string{"start", "resumed", "end", "deferred"}},
+	}
+	for i, step := range steps {
+		if status := n.ExecuteTick(); status != step.status || n.Status() != step.status {
+			t.Fatalf("tick %d: status %v (node %v), want %v", i, status, n.Status(), step.status)
+		}
+		if !reflect.DeepEqual(n.log, step.log) {
+			t.Fatalf("tick %d: log %v, want %v", i, n.log, step.log)
+		}
+	}
+
+	// the next execution starts from the beginning
+	n.ResetStatus()
+	n.log = nil
+	if status := n.ExecuteTick(); status != NodeStatus_RUNNING || !reflect.DeepEqual(n.log, []string{"start"}) {
+		t.Fatalf("restarted: status %v, log %v", status, n.log)
+	}
+	n.HaltNode()
+}
+
+func TestCoroActionNodeHalt(t *testing.T) {
+	n := newCoroTestNode(t)
+	n.ExecuteTick()
+	n.HaltNode()
+	if want := []string{"start", "deferred"}; !reflect.DeepEqual(n.log, want) {
+		t.Fatalf("log after Halt %v, want %v", n.log, want)
+	}
+	if n.Status() != NodeStatus_IDLE {
+		t.Fatalf("status after Halt %v, want IDLE", n.Status())
+	}
+	// a halted node starts again, and Halt without a running coroutine does nothing
+	n.log = nil
+	if status := n.ExecuteTick(); status != NodeStatus_RUNNING || !reflect.DeepEqual(n.log, []string{"start"}) {
+		t.Fatalf("after Halt: status %v, log %v", status, n.log)
+	}
+	n.HaltNode()
+	n.HaltNode()
+}
+
+func TestCoroActionNodePanic(t *testing.T) {
+	n := newCoroTestNode(t)
+	cause := errors.New("failure in Tick")
+	n.panic = cause
+	n.ExecuteTick()
+
+	func() {
+		defer func() {
+			if r := recover(); r != cause {
+				t.Fatalf("recovered %v, want the panic of Tick", r)
+			}
+		}()
+		n.ExecuteTick()
+	}()
+	if want := []string{"start", "resumed", "deferred"}; !reflect.DeepEqual(n.log, want) {
+		t.Fatalf("log %v, want %v", n.log, want)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Yield outside of Tick didn't panic")
+		}
+	}()
+	n.Yield()
+}
+
+// threadedTestNode runs the tick function given by the test
+type threadedTestNode struct {
+	*ThreadedAction
+	tick func(n *threadedTestNode) NodeStatus
+}
+
+func (n *threadedTestNode) Tick() NodeStatus {
+	return n.tick(n)
+}
+
+func newThreadedTestNode(t *testing.T, tick func(n *threadedTestNode) NodeStatus) *threadedTestNode {
+	return newActionTestNode(t, func(name string, cfg *NodeConfig) *threadedTestNode {
+		return &threadedTestNode{ThreadedAction: NewThreadedAction(name, cfg), tick: tick}
+	})
+}
+
+// waitStatus polls the status of the node until it is not RUNNING
+func waitStatus(t *testing.T, n ITreeNode) NodeStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for n.Status() == NodeStatus_RUNNING {
+		if time.Now().After(deadline) {
+			t.Fatal("the node is still RUNNING")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return n.Status()
+}
+
+func TestThreadedAction(t *testing.T) {
+	release := make(chan struct{})
+	n := newThreadedTestNode(t, func(n *threadedTestNode) NodeStatus {
+		<-release
+		return NodeStatus_SUCCESS
+	})
+	if status := n.ExecuteTick(); status != NodeStatus_RUNNING {
+		t.Fatalf("status %v, want RUNNING", status)
+	}
+	if n.IsHaltRequested() {
+		t.Fatal("halt requested while running")
+	}
+	// Tick isn't started again while running
+	if status := n.ExecuteTick(); status != NodeStatus_RUNNING {
+		t.Fatalf("status %v, want RUNNING", status)
+	}
+	close(release)
+	if status := waitStatus(t, n); status != NodeStatus_SUCCESS {
+		t.Fatalf("status %v, want SUCCESS", status)
+	}
+	if !n.IsHaltRequested() {
+		t.Fatal("the context isn't canceled when Tick returns")
+	}
+}
+
+func TestThreadedActionPanic(t *testing.T) {
+	cause := errors.New("failure in Tick")
+	panicked := false
+	n := newThreadedTestNode(t, func(n *threadedTestNode) NodeStatus {
+		if !panicked {
+			panicked = true
+			panic(cause)
+		}
+		return NodeStatus_SUCCESS
+	})
+	n.ExecuteTick()
+	if status := waitStatus(t, n); status != NodeStatus_IDLE {
+		t.Fatalf("status after the panic %v, want IDLE", status)
+	}
+
+	func() {
+		defer func() {
+			err, ok := recover().(*ThreadedActionError)
+			if !ok {
+				t.Fatalf("ExecuteTick didn't panic with a *ThreadedActionError")
+			}
+			if err.Path != "node" || !errors.Is(err, cause) || len(err.Stack) == 0 {
+				t.Fatalf("error %v (path %v), want the panic of Tick at node", err, err.Path)
+			}
+		}()
+		n.ExecuteTick()
+	}()
+
+	// the error is raised once, then Tick runs again
+	n.ExecuteTick()
+	if status := waitStatus(t, n); status != NodeStatus_SUCCESS {
+		t.Fatalf("status %v, want SUCCESS", status)
+	}
+}
+
+func TestThreadedActionHalt(t *testing.T) {
+	var done sync.WaitGroup
+	done.Add(1)
+	n := newThreadedTestNode(t, func(n *threadedTestNode) NodeStatus {
+		defer done.Done()
+		<-n.Context().Done()
+		return NodeStatus_SUCCESS
+	})
+	n.ExecuteTick()
+	n.HaltNode()
+	// Halt waits for Tick, whose result is discarded
+	done.Wait()
+	if n.Status() != NodeStatus_IDLE || !n.IsHaltRequested() {
+		t.Fatalf("status after Halt %v, halt requested %v", n.Status(), n.IsHaltRequested())
+	}
+}
+
+func TestThreadedActionHaltTimeout(t *testing.T) {
+	release, returned := make(chan struct{}), make(chan struct{})
+	n := newThreadedTestNode(t, func(n *threadedTestNode) NodeStatus {
+		// ignores the context
+		defer close(returned)
+		<-release
+		return NodeStatus_SUCCESS
+	})
+	const timeout = 50 * time.Millisecond
+	n.SetHaltTimeout(timeout)
+	n.ExecuteTick()
+
+	start := time.Now()
+	n.HaltNode()
+	if elapsed := time.Since(start); elapsed < timeout {
+		t.Fatalf("Halt returned after %v, before the timeout", elapsed)
+	}
+	if n.Status() != NodeStatus_IDLE {
+		t.Fatalf("status after Halt %v, want IDLE", n.Status())
+	}
+
+	// the late result of Tick doesn't change the status
+	close(release)
+	<-returned
+	time.Sleep(10 * time.Millisecond)
+	if n.Status() != NodeStatus_IDLE {
+		t.Fatalf("status after the late return of Tick %v, want IDLE", n.Status())
+	}
+}
@@ -3,9 +3,11 @@ package core
 import (
 	"container/list"
 	"errors"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 func IsAlpha(c uint8) bool {
@@ -23,6 +25,20 @@ type IMetadata interface {
 type IGetProvidedPorts interface {
 	GetProvidedPorts() map[string]*PortInfo
 }
+
+// IScriptExecutor is implemented by the nodes that compile a script read from their ports.
+// The factory calls LoadExecutor once the node is created, so that a malformed script
+// makes the creation of the tree fail, instead of the first tick.
+type IScriptExecutor interface {
+	LoadExecutor() error
+}
+
+// IParentNode is implemented by the control and decorator nodes, to visit the structure of a tree
+type IParentNode interface {
+	GetChildren() []ITreeNode
+}
+
+type StatusChangeCallback func(timestamp time.Time, node *TreeNode, prev, status NodeStatus)
 type PostTickCallback func(node *TreeNode, status NodeStatus) NodeStatus
 type PreTickCallback func(node *TreeNode) NodeStatus
 type ScriptFunction func(args ...interface{}) bool
@@ -37,15 +53,21 @@ type ITreeNode interface {
 	PreConditionsScripts() []ScriptFunction
 	PostConditionsScripts() []ScriptFunction
 	SetRegistrationID(ID string)
+	RegistrationName() string
 
 	NodeType() NodeType
 	UID() uint16
+	Name() string
+	FullPath() string
 	HaltNode()
 
 	SetWakeUpInstance(instance *WakeUpSignal)
 	ExecuteTick() NodeStatus
 	ResetStatus()
 	Status() NodeStatus
+	SubscribeToStatusChange(callback StatusChangeCallback) *Subscriber
+	SetPreTickFunction(callback func(node *TreeNode) NodeStatus)
+	SetPostTickFunction(callback func(node *TreeNode, status NodeStatus) NodeStatus)
 }
 
 func IsAllowedPortName(str string) bool {
@@ -72,8 +94,31 @@ type IFromStr interface {
 	FromString(str string) error
 }
 
+// JsonPortPrefix marks the port strings that contain a JSON, decoded by the JsonExporter
+const JsonPortPrefix = "json:"
+
+// ConvFromString parses str into the variable pointed by value. It tries, in order: a JSON with
+// JsonPortPrefix, the converters registered with RegisterConverter, the builtin types, IFromStr
+// and the slices of any of them, with the elements separated by semicolons.
 func ConvFromString(str string, value any) error {
+	if strings.HasPrefix(str, JsonPortPrefix) {
+		return GetJsonExporter().FromJsonTo([]byte(str[len(JsonPortPrefix):]), value)
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("ConvFromString requires a non-nil pointer")
+	}
+	if conv, ok := GetConverter(rv.Type().Elem()); ok {
+		res, err := conv(str)
+		if err != nil {
+			return err
+		}
+		rv.Elem().Set(reflect.ValueOf(res))
+		return nil
+	}
 	switch v := value.(type) {
+	case *string:
+		*v = str
 	case *int:
 		res, err := ConvertInt64FromString(str)
 		if err != nil {
@@ -86,12 +131,6 @@ func ConvFromString(str string, value any) error {
 			return err
 		}
 		*v = res
-	case *[]float64:
-		res, err := ConvertFloat64sFromString(str)
-		if err != nil {
-			return err
-		}
-		*v = append(*v, res...)
 	case *int32:
 		res, err := ConvertInt64FromString(str)
 		if err != nil {
@@ -104,18 +143,15 @@ func ConvFromString(str string, value any) error {
 			return err
 		}
 		*v = res
-	case *[]int64:
-		res, err := ConvertInt64sFromString(str)
-		if err != nil {
-			return err
-		}
-		*v = append(*v, res...)
 	case *bool:
 		*v = ConvertBoolFromString(str)
 	default:
 		if tmp, ok := value.(IFromStr); ok {
 			return tmp.FromString(str)
 		}
+		if rv.Elem().Kind() == reflect.Slice {
+			return convertSliceFromString(str, rv)
+		}
 		return errors.New("invalid format")
 	}
 	return nil
@@ -172,7 +208,3 @@ func ConvertFloat64FromString(str string) (res float64, err error) {
 func ConvertInt64FromString(str string) (res int64, err error) {
 	return strconv.ParseInt(str, 10, 64)
 }
-
-func ParseScript(s string) (ScriptFunction, error) {
-	return nil, nil
-}
@@ -1,9 +1,19 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
+)
+
+var (
+	ErrMissingKey         = errors.New("missing key")
+	ErrUninitializedEntry = errors.New("entry not initialized")
+	ErrTypeMismatch       = errors.New("type mismatch")
+	ErrMissingPort        = errors.New("port not found")
 )
 
 type Blackboard struct {
@@ -12,6 +22,7 @@ type Blackboard struct {
 	parentBb           *Blackboard
 	internalToExternal map[string]string
 	automapping        bool
+	changeSignal       *Signal
 }
 
 func NewBlackboard(parent *Blackboard) *Blackboard {
@@ -19,12 +30,55 @@ func NewBlackboard(parent *Blackboard) *Blackboard {
 		internalToExternal: map[string]string{},
 		storage:            map[string]*Entry{},
 		parentBb:           parent,
+		changeSignal:       NewSignal(),
 	}
 }
 
 type Entry struct {
 	entryMutex sync.Mutex
 	Value      any
+	// the port that created the entry
+	Info       *PortInfo
+	sequenceId uint64
+	stamp      time.Time
+	// full path of the node whose port created the entry, if any
+	createdBy string
+}
+
+// Type returns the type of the port that created the entry or, if it accepts any type,
+// the type of the value; nil if both are unknown. The caller holds entryMutex.
+func (e *Entry) Type() reflect.Type {
+	if e.Info != nil && e.Info.IsStronglyTyped() {
+		return e.Info.Type()
+	}
+	return reflect.TypeOf(e.Value)
+}
+
+// update sets the value and marks the entry as written; the caller holds entryMutex
+func (e *Entry) update(value any) {
+	e.Value = value
+	e.sequenceId++
+	e.stamp = time.Now()
+}
+
+// EntryInfo describes the last write of an entry
+type EntryInfo struct {
+	// incremented every time the entry is written; 0 if it was never written
+	SequenceId uint64
+	// time of the last write; zero if it was never written
+	Stamp time.Time
+	Type  reflect.Type
+}
+
+// GetEntryInfo returns the metadata of the entry, following the remapping as GetEntry.
+func (n *Blackboard) GetEntryInfo(key string) (info EntryInfo, ok bool) {
+	entry := n.GetEntry(key)
+	if entry == nil {
+		return info, false
+	}
+	entry.entryMutex.Lock()
+	defer entry.entryMutex.Unlock()
+	return EntryInfo{SequenceId: entry.sequenceId, Stamp: entry.stamp, Type: entry.Type()}, true
 }
 
 func (n *Blackboard) Clear() {
@@ -60,29 +114,36 @@ func (n *Blackboard) Unset(key string) {
 
 func (n *Blackboard) GetAnyLocked(key string) func() *Entry {
 	return func() (entry *Entry) {
-		n.mutex_.Lock()
-		entry = n.GetEntry(key)
-		n.mutex_.Unlock()
-		return entry
+		return n.GetEntry(key)
 	}
 }
 
 func (n *Blackboard) AddSubtreeRemapping(internal, external string) {
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
 	n.internalToExternal[internal] = external
 }
 
+// DebugMessage prints the keys with the type of their values, and the remappings to the parent
 func (n *Blackboard) DebugMessage() {
-	for key, entry := range n.storage {
-		fmt.Printf("%v (%v)", key, reflect.ValueOf(entry.Value).String())
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
+	for _, key := range sortedKeys(n.storage) {
+		entry := n.storage[key]
+		entry.entryMutex.Lock()
+		fmt.Printf("%v (%T)\n", key, entry.Value)
+		entry.entryMutex.Unlock()
 	}
-
-	for from, to := range n.internalToExternal {
-		fmt.Printf("[%v] remapped to port of parent tree [%v]", from, to)
-		continue
+	for _, from := range sortedKeys(n.internalToExternal) {
+		fmt.Printf("[%v] remapped to port of parent tree [%v]\n", from, n.internalToExternal[from])
 	}
 }
 
+// GetKeys returns the keys of the entries of the blackboard, including the entries of the
+// parent already accessed through a remapping
 func (n *Blackboard) GetKeys() (res []string) {
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
 	if len(n.storage) == 0 {
 		return
 	}
@@ -121,6 +182,192 @@ func (n *Blackboard) GetEntry(key string) *Entry {
 	return nil
 }
 
+// isRemapped tells if the entry of the key belongs to the parent blackboard
+func (n *Blackboard) isRemapped(key string) bool {
+	if n.parentBb == nil {
+		return false
+	}
+	if _, ok := n.internalToExternal[key]; ok {
+		return true
+	}
+	return n.automapping && !IsPrivateKey(key)
+}
+
+// Clone returns a deep copy of the blackboard, that can be modified without affecting the original.
+// The parent blackboards are cloned too, so that the remapped entries are independent as well.
+func (n *Blackboard) Clone() *Blackboard {
+	var parent *Blackboard
+	if n.parentBb != nil {
+		parent = n.parentBb.Clone()
+	}
+	res := n.cloneLocal(parent)
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
+	for internal, external := range n.internalToExternal {
+		res.internalToExternal[internal] = external
+	}
+	res.automapping = n.automapping
+	return res
+}
+
+// cloneLocal returns a blackboard with the given parent and a deep copy of the entries
+// owned by n; the entries remapped to the parent are not copied.
+func (n *Blackboard) cloneLocal(parent *Blackboard) *Blackboard {
+	res := NewBlackboard(parent)
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
+	for key, entry := range n.storage {
+		if n.isRemapped(key) {
+			continue
+		}
+		entry.entryMutex.Lock()
+		res.storage[key] = &Entry{Value: deepCopy(entry.Value), Info: entry.Info, sequenceId: entry.sequenceId, stamp: entry.stamp, createdBy: entry.createdBy}
+		entry.entryMutex.Unlock()
+	}
+	return res
+}
+
+// blackboardChange is a write of an entry, to be notified to the watchers
+type blackboardChange struct {
+	key         string
+	prev, value any
+}
+
+// cloneInto copies the entries owned by n into dst, updating the existing entries in place.
+// The entries owned by dst that don't exist in n are removed. Only the entries whose value
+// differs are written; the changes are returned, to notify them once the locks are released.
+func (n *Blackboard) cloneInto(dst *Blackboard) (changes []blackboardChange) {
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
+	dst.mutex_.Lock()
+	defer dst.mutex_.Unlock()
+	for key, entry := range dst.storage {
+		if _, ok := n.storage[key]; !ok && !dst.isRemapped(key) {
+			delete(dst.storage, key)
+			entry.entryMutex.Lock()
+			changes = append(changes, blackboardChange{key: key, prev: entry.Value})
+			entry.entryMutex.Unlock()
+		}
+	}
+	for key, src := range n.storage {
+		if n.isRemapped(key) || dst.isRemapped(key) {
+			continue
+		}
+		src.entryMutex.Lock()
+		value, info, createdBy := deepCopy(src.Value), src.Info, src.createdBy
+		src.entryMutex.Unlock()
+		entry, ok := dst.storage[key]
+		if !ok {
+			dst.storage[key] = &Entry{Value: value, Info: info, sequenceId: 1, stamp: time.Now(), createdBy: createdBy}
+			changes = append(changes, blackboardChange{key: key, value: value})
+			continue
+		}
+		entry.entryMutex.Lock()
+		prev := entry.Value
+		if !reflect.DeepEqual(prev, value) {
+			entry.update(value)
+			changes = append(changes, blackboardChange{key: key, prev: prev, value: value})
+		}
+		entry.entryMutex.Unlock()
+	}
+	return changes
+}
+
+// dropRemappedEntries forgets the entries of the parent cached by GetEntry,
+// so that they are searched again in the parent
+func (n *Blackboard) dropRemappedEntries() {
+	n.mutex_.Lock()
+	defer n.mutex_.Unlock()
+	for key := range n.storage {
+		if n.isRemapped(key) {
+			delete(n.storage, key)
+		}
+	}
+}
+
+// deepCopy copies the slices, maps, arrays, pointers and structs contained in value.
+// Channels, functions, unexported struct fields and the pointers to structs with unexported
+// fields (e.g. *list.List, whose internals can't be copied safely) are copied by reference.
+func deepCopy(value any) any {
+	if value == nil {
+		return nil
+	}
+	return deepCopyValue(reflect.ValueOf(value), map[uintptr]reflect.Value{}).Interface()
+}
+
+func deepCopyValue(v reflect.Value, visited map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		if res, ok := visited[v.Pointer()]; ok {
+			return res
+		}
+		if hasUnexportedFields(v.Type().Elem()) {
+			return v
+		}
+		res := reflect.New(v.Type().Elem())
+		visited[v.Pointer()] = res
+		res.Elem().Set(deepCopyValue(v.Elem(), visited))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(deepCopyValue(v.Elem(), visited))
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(deepCopyValue(v.Index(i), visited))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(deepCopyValue(v.Index(i), visited))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(deepCopyValue(iter.Key(), visited), deepCopyValue(iter.Value(), visited))
+		}
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if res.Field(i).CanSet() {
+				res.Field(i).Set(deepCopyValue(v.Field(i), visited))
+			}
+		}
+		return res
+	}
+	return v
+}
+
+func hasUnexportedFields(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
 func (n *Blackboard) CreateEntry(key string, info *PortInfo) *Entry {
 	return n.createEntryImpl(key, info)
 }
@@ -151,7 +398,7 @@ func (n *Blackboard) createEntryImpl(key string, info *PortInfo) *Entry {
 		}
 	} else {
 		// not remapped, not found. Create locally.
-		entry = &Entry{}
+		entry = &Entry{Info: info}
 		// even if empty, let's assign to it a default type
 		entry.Value = info.defaultValue
 
@@ -162,52 +409,210 @@ func (n *Blackboard) createEntryImpl(key string, info *PortInfo) *Entry {
 }
 
 func (n *Blackboard) Set(key string, value any) {
+	n.mustSet(key, value, nil)
+}
+
+func (n *Blackboard) mustSet(key string, value any, writer *TreeNode) {
+	err := n.set(key, value, nil, writer)
+	if err != nil {
+		n.DebugMessage()
+		panic(fmt.Sprintf("Blackboard::set(%v): %v", key, err))
+	}
+}
+
+// set writes the entry and notifies the watchers; writer is the node that set the value, if any.
+// If typ isn't nil, the entry is created with that type, or must already have it.
+func (n *Blackboard) set(key string, value any, typ reflect.Type, writer *TreeNode) error {
+	prev, stored, err := n.store(key, value, typ)
+	if err != nil {
+		return err
+	}
+	n.notifyChange(key, prev, stored, writer)
+	return nil
+}
+
+// store writes the entry and returns the previous and the stored value
+func (n *Blackboard) store(key string, value any, typ reflect.Type) (prev, stored any, err error) {
 	n.mutex_.Lock()
-	defer n.mutex_.Unlock()
 	entry, ok := n.storage[key]
+	n.mutex_.Unlock()
 	if !ok {
-		n.mutex_.Unlock()
+		// the entry might exist already in a parent blackboard
+		entry = n.GetEntry(key)
+	}
+	if entry == nil {
 		// if a new generic port is created with a string, it's type should be AnyTypeAllowed
 		s, ok := value.(string)
 		p := NewPortInfo(PortDirection_INOUT, "")
 		p.defaultValueStr = s
-		if ok {
+		if typ != nil {
+			p.typ = typ
+			entry = n.createEntryImpl(key, p)
+		} else if ok {
 			entry = n.createEntryImpl(key, p)
 		} else {
 			p.SetDefaultValue(value)
 			entry = n.createEntryImpl(key, p)
 		}
-		n.mutex_.Lock()
-		n.storage[key] = entry
-		entry.Value = value
-	} else {
-		// this is not the first time we set this entry, we need to check
-		// if the type is the same or not.
-
 		entry.entryMutex.Lock()
 		defer entry.entryMutex.Unlock()
-		if entry == nil || reflect.TypeOf(entry.Value) == nil {
-			entry.Value = value
-			entry.entryMutex.Unlock()
+		entry.update(value)
+		return nil, value, nil
+	}
+	// this is not the first time we set this entry, we need to check
+	// if the type is the same or not.
+
+	entry.entryMutex.Lock()
+	defer entry.entryMutex.Unlock()
+	prev = entry.Value
+	previousType := entry.Type()
+	// string entries without a value accept any type, as the ports without type
+	if previousType == nil || (entry.Value == nil && previousType.Kind() == reflect.String) {
+		entry.update(value)
+		return prev, value, nil
+	}
+	if typ != nil && previousType != typ {
+		return nil, nil, fmt.Errorf("%w: the entry has type [%v], not [%v]", ErrTypeMismatch, previousType, typ)
+	}
+
+	// check type mismatch
+	if previousType != reflect.TypeOf(value) {
+		if converted, err := convertValue(value, previousType); err == nil {
+			entry.update(converted)
+			return prev, converted, nil
+		}
+		return nil, nil, fmt.Errorf("%w: once declared, the type of a port shall not change. Previously declared type [%v], current type [%T]",
+			ErrTypeMismatch, previousType, value)
+	}
+	entry.update(value)
+	return prev, value, nil
+}
+
+// BlackboardChangeCallback receives the key written, the previous and the new value and the node
+// that wrote it with SetOutput; writer is nil when the value was set directly on the blackboard.
+type BlackboardChangeCallback func(key string, prev, value any, writer *TreeNode)
+
+// Watch registers a callback invoked every time the entry is written, in this blackboard or in a child
+// blackboard that remaps (or autoremaps) one of its keys to it. The callback is executed synchronously,
+// by the goroutine that wrote the entry. Use the returned Subscriber to stop receiving the notifications.
+func (n *Blackboard) Watch(key string, callback BlackboardChangeCallback) *Subscriber {
+	return n.changeSignal.Subscribe(func(args ...any) {
+		if args[0].(string) == key {
+			callback(args[0].(string), args[1], args[2], args[3].(*TreeNode))
+		}
+	})
+}
+
+// WatchPrefix is like Watch, but the callback is invoked for all the keys starting with prefix
+func (n *Blackboard) WatchPrefix(prefix string, callback BlackboardChangeCallback) *Subscriber {
+	return n.changeSignal.Subscribe(func(args ...any) {
+		if strings.HasPrefix(args[0].(string), prefix) {
+			callback(args[0].(string), args[1], args[2], args[3].(*TreeNode))
+		}
+	})
+}
+
+// notifyChange notifies the watchers of this blackboard, then those of the parents
+// the key is remapped to, with the name of the key in each blackboard.
+func (n *Blackboard) notifyChange(key string, prev, value any, writer *TreeNode) {
+	for bb := n; bb != nil; {
+		bb.changeSignal.Notify(key, prev, value, writer)
+		bb.mutex_.Lock()
+		parent, remapped := bb.parentBb, bb.isRemapped(key)
+		if external, ok := bb.internalToExternal[key]; ok {
+			key = external
+		}
+		bb.mutex_.Unlock()
+		if !remapped {
 			return
 		}
+		bb = parent
+	}
+}
 
-		previousType := reflect.TypeOf(entry.Value)
+// BBGet returns the value of the entry as T. The stored value is converted if it is
+// a number that can be widened to T (e.g. int to float64), or a string that can be parsed
+// by ConvFromString. The errors wrap ErrMissingKey, ErrUninitializedEntry or ErrTypeMismatch.
+func BBGet[T any](bb *Blackboard, key string) (res T, err error) {
+	entry := bb.GetEntry(key)
+	if entry == nil {
+		return res, fmt.Errorf("%w: [%v]", ErrMissingKey, key)
+	}
+	entry.entryMutex.Lock()
+	value := entry.Value
+	entry.entryMutex.Unlock()
+	if value == nil {
+		return res, fmt.Errorf("%w: [%v]", ErrUninitializedEntry, key)
+	}
+	converted, err := convertValue(value, reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return res, fmt.Errorf("%w: entry [%v]", err, key)
+	}
+	return converted.(T), nil
+}
 
-		// check type mismatch
-		if previousType != reflect.TypeOf(value) {
-			mismatching := true
-			if v, ok := value.(fmt.Stringer); ok {
-				anyFromString := v.String()
-				if anyFromString != "" {
-					mismatching = false
-					entry.Value = anyFromString
-				}
-			}
-			if mismatching {
-				n.DebugMessage()
-				panic(fmt.Sprintf("Blackboard::set(%v", key, "): once declared, the type of a port shall not change. Previously declared type [, reflect.TypeOf(previous_type),], current type [", "]"))
+// BBGetOr is like BBGet, but returns defaultValue in case of error
+func BBGetOr[T any](bb *Blackboard, key string, defaultValue T) T {
+	res, err := BBGet[T](bb, key)
+	if err != nil {
+		return defaultValue
+	}
+	return res
+}
+
+// BBSet sets the entry, creating it if necessary with the type T: unlike Set, a new entry
+// created with a string is strongly typed. If the entry already has a type other than T,
+// ErrTypeMismatch is returned. With an interface type, e.g. BBSet[any], the type isn't
+// checked and the value is converted to the type of the entry, as in Set.
+func BBSet[T any](bb *Blackboard, key string, value T) error {
+	typ := typeOf[T]()
+	if typ.Kind() == reflect.Interface {
+		typ = nil
+	}
+	return bb.set(key, value, typ, nil)
+}
+
+// convertValue returns value as the type typ, widening the numbers and parsing the strings
+func convertValue(value any, typ reflect.Type) (res any, err error) {
+	if value == nil {
+		return nil, fmt.Errorf("%w: can't convert nil to [%v]", ErrTypeMismatch, typ)
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type() == typ || (typ.Kind() == reflect.Interface && rv.Type().Implements(typ)) {
+		return value, nil
+	}
+	if widenNumber(rv.Type(), typ) {
+		return rv.Convert(typ).Interface(), nil
+	}
+	if str, ok := value.(string); ok {
+		defer func() {
+			if r := recover(); r != nil {
+				res, err = nil, fmt.Errorf("%w: can't convert [%v] to [%v]: %v", ErrTypeMismatch, str, typ, r)
 			}
+		}()
+		ptr := reflect.New(typ)
+		err = ConvFromString(str, ptr.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("%w: can't convert [%v] to [%v]: %v", ErrTypeMismatch, str, typ, err)
 		}
+		return ptr.Elem().Interface(), nil
+	}
+	return nil, fmt.Errorf("%w: stored [%v], requested [%v]", ErrTypeMismatch, rv.Type(), typ)
+}
+
+// widenNumber tells if the numbers of the type from can be converted to the type to without loss,
+// or, for integers to floats, with the usual loss of precision of the large values.
+func widenNumber(from, to reflect.Type) bool {
+	isInt := func(k reflect.Kind) bool { return k >= reflect.Int && k <= reflect.Int64 }
+	isUint := func(k reflect.Kind) bool { return k >= reflect.Uint && k <= reflect.Uint64 }
+	isFloat := func(k reflect.Kind) bool { return k == reflect.Float32 || k == reflect.Float64 }
+	switch fk, tk := from.Kind(), to.Kind(); {
+	case isInt(fk) && isInt(tk), isUint(fk) && isUint(tk), isFloat(fk) && isFloat(tk):
+		return to.Bits() >= from.Bits()
+	case isUint(fk) && isInt(tk):
+		return to.Bits() > from.Bits()
+	case (isInt(fk) || isUint(fk)) && isFloat(tk):
+		return tk == reflect.Float64 || from.Bits() <= 16
 	}
+	return false
 }
@@ -0,0 +1,317 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stringerValue is a value that isn't a string, but can be printed as one
+type stringerValue struct{}
+
+func (stringerValue) String() string { return "stringer" }
+
+// newPortTestNode returns a node with the input ports given, reading the blackboard bb
+func newPortTestNode(t *testing.T, bb *Blackboard, inputs map[string]string) ITreeNode {
+	t.Helper()
+	f := NewBehaviorTreeFactory()
+	f.RegisterSimpleAction("PortTest", func(node ITreeNode, status ...NodeStatus) NodeStatus {
+		return NodeStatus_SUCCESS
+	}, InputPort("value"))
+	node, err := f.InstantiateTreeNode("node", "PortTest", &NodeConfig{Blackboard: bb, InputPorts: inputs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+func TestBlackboardSentinelErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(bb *Blackboard) error
+		want error
+	}{
+		{"BBGet missing key", func(bb *Blackboard) error {
+			_, err := BBGet[int](bb, "missing")
+			return err
+		}, ErrMissingKey},
+		{"BBGet entry without value", func(bb *Blackboard) error {
+			bb.CreateEntry("empty", NewPortInfo(PortDirection_INOUT, "empty"))
+			_, err := BBGet[int](bb, "empty")
+			return err
+		}, ErrUninitializedEntry},
+		{"BBGet string not a number", func(bb *Blackboard) error {
+			bb.Set("str", "abc")
+			_, err := BBGet[int](bb, "str")
+			return err
+		}, ErrTypeMismatch},
+		{"BBGet narrowing", func(bb *Blackboard) error {
+			bb.Set("big", int64(1))
+			_, err := BBGet[int32](bb, "big")
+			return err
+		}, ErrTypeMismatch},
+		{"BBSet other type", func(bb *Blackboard) error {
+			bb.Set("int", 1)
+			return BBSet(bb, "int", "1")
+		}, ErrTypeMismatch},
+		{"Stringer in a typed string entry", func(bb *Blackboard) error {
+			if err := BBSet(bb, "str", "abc"); err != nil {
+				return err
+			}
+			return BBSet[any](bb, "str", stringerValue{})
+		}, ErrTypeMismatch},
+		{"Stringer in an int entry", func(bb *Blackboard) error {
+			bb.Set("int", 1)
+			return BBSet[any](bb, "int", stringerValue{})
+		}, ErrTypeMismatch},
+		{"GetInput undeclared port", func(bb *Blackboard) error {
+			_, err := GetInput[int](newPortTestNode(t, bb, map[string]string{}), "value")
+			return err
+		}, ErrMissingPort},
+		{"GetInput missing key", func(bb *Blackboard) error {
+			_, err := GetInput[int](newPortTestNode(t, bb, map[string]string{"value": "{missing}"}), "value")
+			return err
+		}, ErrMissingKey},
+		{"GetInput entry without value", func(bb *Blackboard) error {
+			bb.CreateEntry("empty", NewPortInfo(PortDirection_INOUT, "empty"))
+			_, err := GetInput[int](newPortTestNode(t, bb, map[string]string{"value": "{empty}"}), "value")
+			return err
+		}, ErrUninitializedEntry},
+		{"GetInput wrong type", func(bb *Blackboard) error {
+			bb.Set("str", "abc")
+			_, err := GetInput[int](newPortTestNode(t, bb, map[string]string{"value": "{str}"}), "value")
+			return err
+		}, ErrTypeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(NewBlackboard(nil))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBBGetSet(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(bb *Blackboard) error
+		get  func(bb *Blackboard) (any, error)
+		want any
+	}{
+		{"same type", func(bb *Blackboard) error { return BBSet(bb, "k", 42) },
+			func(bb *Blackboard) (any, error) { return BBGet[int](bb, "k") }, 42},
+		{"int to float64", func(bb *Blackboard) error { return BBSet(bb, "k", 42) },
+			func(bb *Blackboard) (any, error) { return BBGet[float64](bb, "k") }, 42.0},
+		{"int32 to int64", func(bb *Blackboard) error { return BBSet(bb, "k", int32(-7)) },
+			func(bb *Blackboard) (any, error) { return BBGet[int64](bb, "k") }, int64(-7)},
+		{"uint8 to int", func(bb *Blackboard) error { return BBSet(bb, "k", uint8(200)) },
+			func(bb *Blackboard) (any, error) { return BBGet[int](bb, "k") }, 200},
+		{"float32 to float64", func(bb *Blackboard) error { return BBSet(bb, "k", float32(0.5)) },
+			func(bb *Blackboard) (any, error) { return BBGet[float64](bb, "k") }, 0.5},
+		{"string parsed", func(bb *Blackboard) error { bb.Set("k", "3.5"); return nil },
+			func(bb *Blackboard) (any, error) { return BBGet[float64](bb, "k") }, 3.5},
+		{"Set converts to the entry type", func(bb *Blackboard) error {
+			if err := BBSet(bb, "k", 1); err != nil {
+				return err
+			}
+			return BBSet[any](bb, "k", "42")
+		}, func(bb *Blackboard) (any, error) { return BBGet[int](bb, "k") }, 42},
+		{"Set widens to the entry type", func(bb *Blackboard) error {
+			if err := BBSet(bb, "k", 1.5); err != nil {
+				return err
+			}
+			return BBSet[any](bb, "k", 2)
+		}, func(bb *Blackboard) (any, error) { return bb.GetEntry("k").Value, nil }, 2.0},
+		{"BBGetOr missing key", func(bb *Blackboard) error { return nil },
+			func(bb *Blackboard) (any, error) { return BBGetOr(bb, "k", 7), nil }, 7},
+		{"BBGetOr type mismatch", func(bb *Blackboard) error { return BBSet(bb, "k", "abc") },
+			func(bb *Blackboard) (any, error) { return BBGetOr(bb, "k", 7), nil }, 7},
+		{"BBGetOr value", func(bb *Blackboard) error { return BBSet(bb, "k", 3) },
+			func(bb *Blackboard) (any, error) { return BBGetOr(bb, "k", 7.0), nil }, 3.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bb := NewBlackboard(nil)
+			if err := tt.set(bb); err != nil {
+				t.Fatal(err)
+			}
+			got, err := tt.get(bb)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+// blackboardChangeRecord is a notification received by a watcher
+type blackboardChangeRecord struct {
+	key         string
+	prev, value any
+	writer      string
+}
+
+func TestBlackboardWatch(t *testing.T) {
+	tests := []struct {
+		name string
+		// automapping of the child blackboard; [x] is always remapped to [a]
+		autoremap bool
+		// watch subscribes the watchers to the blackboard of the parent and of the child
+		watch func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber
+		write func(child *Blackboard, node ITreeNode)
+		// parent and child are the notifications received by the watchers
+		parent, child []blackboardChangeRecord
+	}{
+		{
+			name:   "remapped key",
+			watch:  func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber { return bb.WatchPrefix("", record) },
+			write:  func(child *Blackboard, node ITreeNode) { child.Set("x", 1); child.Set("x", 2) },
+			parent: []blackboardChangeRecord{{key: "a", value: 1}, {key: "a", prev: 1, value: 2}},
+			child:  []blackboardChangeRecord{{key: "x", value: 1}, {key: "x", prev: 1, value: 2}},
+		},
+		{
+			name:  "local key not notified to the parent",
+			watch: func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber { return bb.WatchPrefix("", record) },
+			write: func(child *Blackboard, node ITreeNode) { child.Set("local", 1) },
+			child: []blackboardChangeRecord{{key: "local", value: 1}},
+		},
+		{
+			name:      "autoremapped key",
+			autoremap: true,
+			watch:     func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber { return bb.Watch("auto", record) },
+			write:     func(child *Blackboard, node ITreeNode) { child.Set("auto", "v"); child.Set("other", "v") },
+			parent:    []blackboardChangeRecord{{key: "auto", value: "v"}},
+			child:     []blackboardChangeRecord{{key: "auto", value: "v"}},
+		},
+		{
+			name:      "private key not autoremapped",
+			autoremap: true,
+			watch:     func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber { return bb.WatchPrefix("_", record) },
+			write:     func(child *Blackboard, node ITreeNode) { child.Set("_private", 1) },
+			child:     []blackboardChangeRecord{{key: "_private", value: 1}},
+		},
+		{
+			name:   "prefix",
+			watch:  func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber { return bb.WatchPrefix("a", record) },
+			write:  func(child *Blackboard, node ITreeNode) { child.Set("x", 1); child.Set("abc", 2); child.Set("b", 3) },
+			parent: []blackboardChangeRecord{{key: "a", value: 1}},
+			child:  []blackboardChangeRecord{{key: "abc", value: 2}},
+		},
+		{
+			name:   "writer of SetOutput",
+			watch:  func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber { return bb.WatchPrefix("", record) },
+			write:  func(child *Blackboard, node ITreeNode) { SetOutput(node, "value", 5) },
+			parent: []blackboardChangeRecord{{key: "a", value: 5, writer: "node"}},
+			child:  []blackboardChangeRecord{{key: "x", value: 5, writer: "node"}},
+		},
+		{
+			name: "unsubscribed",
+			watch: func(bb *Blackboard, record BlackboardChangeCallback) *Subscriber {
+				s := bb.WatchPrefix("", record)
+				s.Unsubscribe()
+				return s
+			},
+			write: func(child *Blackboard, node ITreeNode) { child.Set("x", 1) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := NewBlackboard(nil)
+			child := NewBlackboard(parent)
+			child.AddSubtreeRemapping("x", "a")
+			child.enableAutoRemapping(tt.autoremap)
+
+			var gotParent, gotChild []blackboardChangeRecord
+			recorder := func(res *[]blackboardChangeRecord) BlackboardChangeCallback {
+				return func(key string, prev, value any, writer *TreeNode) {
+					r := blackboardChangeRecord{key: key, prev: prev, value: value}
+					if writer != nil {
+						r.writer = writer.Name()
+					}
+					*res = append(*res, r)
+				}
+			}
+			tt.watch(parent, recorder(&gotParent))
+			tt.watch(child, recorder(&gotChild))
+
+			f := NewBehaviorTreeFactory()
+			f.RegisterSimpleAction("Writer", func(node ITreeNode, status ...NodeStatus) NodeStatus {
+				return NodeStatus_SUCCESS
+			}, OutPort("value"))
+			node, err := f.InstantiateTreeNode("node", "Writer", &NodeConfig{Blackboard: child, OutputPorts: map[string]string{"value": "{x}"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.write(child, node)
+
+			if !reflect.DeepEqual(gotParent, tt.parent) {
+				t.Errorf("parent notified %v, want %v", gotParent, tt.parent)
+			}
+			if !reflect.DeepEqual(gotChild, tt.child) {
+				t.Errorf("child notified %v, want %v", gotChild, tt.child)
+			}
+		})
+	}
+}
+
+func TestEntrySequenceId(t *testing.T) {
+	tests := []struct {
+		name   string
+		write  func(parent, child *Blackboard)
+		key    string
+		wantId uint64
+	}{
+		{"never written", func(parent, child *Blackboard) {
+			child.CreateEntry("k", NewPortInfo(PortDirection_INOUT, "k"))
+		}, "k", 0},
+		{"first write", func(parent, child *Blackboard) { child.Set("k", 1) }, "k", 1},
+		{"every write counts", func(parent, child *Blackboard) {
+			child.Set("k", 1)
+			child.Set("k", 1)
+			child.Set("k", 2)
+		}, "k", 3},
+		{"failed write not counted", func(parent, child *Blackboard) {
+			child.Set("k", 1)
+			_ = BBSet(child, "k", "abc")
+		}, "k", 1},
+		{"remapped entry written by the child", func(parent, child *Blackboard) {
+			parent.Set("a", 1)
+			child.Set("x", 2)
+		}, "a", 2},
+		{"remapped entry written by the parent", func(parent, child *Blackboard) {
+			child.Set("x", 1)
+			parent.Set("a", 2)
+		}, "x", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := NewBlackboard(nil)
+			child := NewBlackboard(parent)
+			child.AddSubtreeRemapping("x", "a")
+			before := time.Now()
+			tt.write(parent, child)
+
+			info, ok := child.GetEntryInfo(tt.key)
+			if !ok {
+				info, ok = parent.GetEntryInfo(tt.key)
+			}
+			if !ok {
+				t.Fatalf("entry [%v] not found", tt.key)
+			}
+			if info.SequenceId != tt.wantId {
+				t.Fatalf("SequenceId %v, want %v", info.SequenceId, tt.wantId)
+			}
+			if written := !info.Stamp.IsZero(); written != (tt.wantId > 0) || (written && info.Stamp.Before(before)) {
+				t.Fatalf("Stamp %v, written after %v: %v", info.Stamp, before, tt.wantId > 0)
+			}
+		})
+	}
+	if _, ok := NewBlackboard(nil).GetEntryInfo("missing"); ok {
+		t.Fatal("GetEntryInfo found a missing entry")
+	}
+}
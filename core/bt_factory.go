@@ -1,14 +1,12 @@
 package core
 
 import (
-	"errors"
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"github.com/gorustyt/go-behavior/actions"
-	"github.com/gorustyt/go-behavior/controls"
-	"github.com/gorustyt/go-behavior/decorators"
 	"log"
-	"reflect"
-	"strings"
+	"plugin"
+	"sort"
 	"time"
 )
 
@@ -46,24 +44,34 @@ func NewTestNodeConfig() *TestNodeConfig {
 	}
 }
 
+type substitutionRule struct {
+	filter string
+	rule   *TestNodeConfig
+}
+
 type BehaviorTreeFactory struct {
 	Builders                map[string]*NodeBuilder
 	behaviorTreeDefinitions map[string]any
 	scriptingEnums          map[string]int
-	substitutionRules       map[string]*TestNodeConfig
+	substitutionRules       []substitutionRule
+	builtinNodes            map[string]struct{}
 	parser                  Parser
 }
 
 func NewBehaviorTreeFactory() *BehaviorTreeFactory {
-	return &BehaviorTreeFactory{
+	f := &BehaviorTreeFactory{
 		Builders:                map[string]*NodeBuilder{},
 		behaviorTreeDefinitions: map[string]any{},
 		scriptingEnums:          map[string]int{},
-		substitutionRules:       map[string]*TestNodeConfig{},
-		parser:                  NewXmlParser(),
+		builtinNodes:            map[string]struct{}{},
 	}
+	f.parser = NewXmlParser(f)
+	return f
 }
 
+// RegisterNodeType registers a node; the manifest is built from a node created by cons with a nil
+// config, so the constructor must accept it. Register builds the manifest from the type instead.
+// A node already registered with the same id, built-in or not, is replaced.
 func (f *BehaviorTreeFactory) RegisterNodeType(id string, cons NodeBuilderFn, args ...any) {
 	b := &NodeBuilder{
 		TreeNodeManifest: NewTreeNodeManifest(cons("", nil, args...)),
@@ -72,6 +80,17 @@ func (f *BehaviorTreeFactory) RegisterNodeType(id string, cons NodeBuilderFn, ar
 	}
 	b.RegistrationID = id
 	f.Builders[id] = b
+	delete(f.builtinNodes, id)
+}
+
+// BuiltinNodes returns the IDs of the nodes registered with RegisterBuiltin, sorted
+func (f *BehaviorTreeFactory) BuiltinNodes() []string {
+	res := make([]string, 0, len(f.builtinNodes))
+	for id := range f.builtinNodes {
+		res = append(res, id)
+	}
+	sort.Strings(res)
+	return res
 }
 
 func (f *BehaviorTreeFactory) InstantiateTreeNode(name, ID string, config *NodeConfig) (node ITreeNode, err error) {
@@ -87,101 +106,245 @@ func (f *BehaviorTreeFactory) InstantiateTreeNode(name, ID string, config *NodeC
 	if !ok || b.TreeNodeManifest == nil {
 		return node, idNotFound()
 	}
-	substituted := false
-	for filter, rule := range f.substitutionRules {
-		if filter == name || filter == ID || strings.Contains(config.Path, filter) { //TODo 验证原来的方法
-			// first case: the rule is simply a string with the name of the
-			// node to create instead
-			if substitutedId := rule.Id; substitutedId != "" {
-				builder, ok := f.Builders[substitutedId]
-				if ok && builder.Cons != nil {
-					node = builder.Cons(name, config)
-				} else {
-					return node, errors.New("substituted Node ID not found")
-				}
-				substituted = true
-				break
-			} else {
-				// second case, the varian is a TestNodeConfig
-				testNode := actions.NewTestNode(name, config)
-				testNode.TestConfig = rule
-				node = testNode
-				substituted = true
-				break
+	rule := f.matchSubstitutionRule(name, ID, config.Path)
+	if rule != nil {
+		// first case: the rule is simply a string with the name of the
+		// node to create instead
+		if substitutedId := rule.Id; substitutedId != "" {
+			builder, ok := f.Builders[substitutedId]
+			if !ok || builder.Cons == nil {
+				return nil, fmt.Errorf("substituted Node ID [%v] not found", substitutedId)
 			}
+			node = builder.Cons(name, config, builder.DefaultArgs...)
+		} else {
+			// second case, the variant is a TestNodeConfig
+			node = NewTestNode(name, config, rule)
 		}
-	}
-
-	// No substitution rule applied: default behavior
-	if !substituted {
+	} else {
+		// No substitution rule applied: default behavior
 		builder, ok := f.Builders[ID]
 		if !ok || builder.Cons == nil {
 			return node, idNotFound()
 		}
-		node = builder.Cons(name, config)
+		node = builder.Cons(name, config, builder.DefaultArgs...)
 	}
 
+	if setter, ok := node.(interface{ setSelf(node ITreeNode) }); ok {
+		setter.setSelf(node)
+	}
 	node.SetRegistrationID(ID)
 	node.Config().Enums = f.scriptingEnums
+	if executor, ok := node.(IScriptExecutor); ok {
+		if err = executor.LoadExecutor(); err != nil {
+			return nil, fmt.Errorf("node [%v]: %v", config.Path, err)
+		}
+	}
 	for condId, script := range config.PreConditions {
-		node.PreConditionsScripts()[condId] = func(args ...interface{}) bool {
-			log.Println("not impl", script)
-			return true
+		executor, err := ParseScript(script)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script in attribute [%v] of node [%v]: %v", condId, config.Path, err)
 		}
+		node.PreConditionsScripts()[condId] = executor
 	}
 
 	for condId, script := range config.PostConditions {
-		node.PostConditionsScripts()[condId] = func(args ...interface{}) bool {
-			log.Println("not impl", script)
-			return true
+		executor, err := ParseScript(script)
+		if err != nil {
+			return nil, fmt.Errorf("invalid script in attribute [%v] of node [%v]: %v", condId, config.Path, err)
 		}
+		node.PostConditionsScripts()[condId] = executor
 	}
 	return node, nil
 }
-func (f *BehaviorTreeFactory) RegisterScriptingEnum(name string, value int) {
-	f.scriptingEnums[name] = value
+
+// AddSubstitutionRule replaces, when the tree is created, the nodes matching filter with another node.
+// The filter is compared with the name and the ID of the node, and matched with the wildcards * and ?
+// against its full path (e.g. "mysub/action_*"). The rule is either the ID of the node to create
+// instead (a string) or the *TestNodeConfig of a TestNode.
+// A rule with the same filter replaces the previous one. A filter equal to the name or the ID of the
+// node wins over the wildcards; otherwise the first rule added, whose filter matches, is applied.
+func (f *BehaviorTreeFactory) AddSubstitutionRule(filter string, rule any) {
+	var config *TestNodeConfig
+	switch v := rule.(type) {
+	case string:
+		config = &TestNodeConfig{Id: v}
+	case *TestNodeConfig:
+		config = v
+	case TestNodeConfig:
+		config = &v
+	default:
+		panic(fmt.Sprintf("AddSubstitutionRule: invalid rule [%T] for the filter [%v]", rule, filter))
+	}
+	for i := range f.substitutionRules {
+		if f.substitutionRules[i].filter == filter {
+			f.substitutionRules[i].rule = config
+			return
+		}
+	}
+	f.substitutionRules = append(f.substitutionRules, substitutionRule{filter: filter, rule: config})
 }
 
-func (f *BehaviorTreeFactory) Init() {
-	f.RegisterNodeType("Fallback", controls.NewFallbackNode)
-	f.RegisterNodeType("AsyncFallback", controls.NewFallbackNode, true)
-	f.RegisterNodeType("Sequence", controls.NewSequenceNode)
-	f.RegisterNodeType("AsyncSequence", controls.NewSequenceNode, true)
-	f.RegisterNodeType("SequenceWithMemory", controls.NewSequenceWithMemory)
-	f.RegisterNodeType("SequenceStar", controls.NewSequenceWithMemory)
-	f.RegisterNodeType("Parallel", controls.NewParallelNode)
-	f.RegisterNodeType("ParallelAll", controls.NewParallelAllNode)
-	f.RegisterNodeType("ReactiveSequence", controls.NewReactiveSequence)
-	f.RegisterNodeType("ReactiveFallback", controls.NewReactiveFallback)
-	f.RegisterNodeType("IfThenElse", controls.NewIfThenElseNode)
-	f.RegisterNodeType("WhileDoElse", controls.NewWhileDoElseNode)
-	f.RegisterNodeType("Inverter", decorators.NewInverterNode)
-	f.RegisterNodeType("RetryUntilSuccessful", decorators.NewRetryNode)
-	f.RegisterNodeType("KeepRunningUntilFailure", decorators.NewKeepRunningUntilFailureNode)
-	f.RegisterNodeType("Repeat", decorators.NewRepeatNode)
-	f.RegisterNodeType("Timeout", decorators.NewTimeoutNode)
-	f.RegisterNodeType("Delay", decorators.NewDelayNode)
-	f.RegisterNodeType("RunOnce", decorators.NewRunOnceNode)
-	f.RegisterNodeType("ForceSuccess", decorators.NewForceSuccessNode)
-	f.RegisterNodeType("ForceFailure", decorators.NewForceFailureNode)
-	f.RegisterNodeType("AlwaysSuccess", actions.NewAlwaysSuccessNode)
-	f.RegisterNodeType("AlwaysFailure", actions.NewAlwaysFailureNode)
-	f.RegisterNodeType("Script", actions.NewScriptNode)
-	f.RegisterNodeType("ScriptCondition", actions.NewScriptCondition)
-	f.RegisterNodeType("SetBlackboard", actions.NewSetBlackboardNode)
-	f.RegisterNodeType("Sleep", actions.NewSleepNode)
-	f.RegisterNodeType("UnsetBlackboard", actions.NewUnsetBlackboardNode)
-	f.RegisterNodeType("SubTree", decorators.NewSubTreeNode)
-	f.RegisterNodeType("Precondition", decorators.NewPreconditionNode)
-	f.RegisterNodeType("Switch2", controls.NewSwitchNode, 2)
-	f.RegisterNodeType("Switch3", controls.NewSwitchNode, 3)
-	f.RegisterNodeType("Switch4", controls.NewSwitchNode, 4)
-	f.RegisterNodeType("Switch5", controls.NewSwitchNode, 5)
-	f.RegisterNodeType("Switch6", controls.NewSwitchNode, 6)
-	f.RegisterNodeType("LoopInt", decorators.NewLoopNode, reflect.Int)
-	f.RegisterNodeType("LoopBool", decorators.NewLoopNode, reflect.Bool)
-	f.RegisterNodeType("LoopDouble", decorators.NewLoopNode, reflect.Float64)
-	f.RegisterNodeType("LoopString", decorators.NewLoopNode, reflect.String)
+func (f *BehaviorTreeFactory) matchSubstitutionRule(name, ID, path string) *TestNodeConfig {
+	for _, r := range f.substitutionRules {
+		if r.filter == name || r.filter == ID {
+			return r.rule
+		}
+	}
+	for _, r := range f.substitutionRules {
+		if WildcardMatch(path, r.filter) {
+			return r.rule
+		}
+	}
+	return nil
+}
+
+// SubstitutionRules returns the rules added with AddSubstitutionRule, by filter
+func (f *BehaviorTreeFactory) SubstitutionRules() map[string]*TestNodeConfig {
+	res := map[string]*TestNodeConfig{}
+	for _, r := range f.substitutionRules {
+		res[r.filter] = r.rule
+	}
+	return res
+}
+
+func (f *BehaviorTreeFactory) ClearSubstitutionRules() {
+	f.substitutionRules = nil
+}
+
+// LoadSubstitutionRulesFromJSON adds the substitution rules of a JSON document like:
+//
+//	{
+//	  "TestNodeConfigs": {
+//	    "MyTest": {"async_delay": 2000, "return_status": "SUCCESS", "post_script": "msg := 'done'"}
+//	  },
+//	  "SubstitutionRules": {
+//	    "mysub/action_*": "TestAction",
+//	    "last_action": "MyTest"
+//	  }
+//	}
+//
+// async_delay is in milliseconds. A rule naming one of the TestNodeConfigs substitutes the node
+// with a TestNode, otherwise it is the ID of the node to create instead.
+func (f *BehaviorTreeFactory) LoadSubstitutionRulesFromJSON(jsonText string) error {
+	var doc struct {
+		TestNodeConfigs map[string]struct {
+			AsyncDelay   int64  `json:"async_delay"`
+			ReturnStatus string `json:"return_status"`
+			PostScript   string `json:"post_script"`
+		}
+		SubstitutionRules json.RawMessage
+	}
+	if err := json.Unmarshal([]byte(jsonText), &doc); err != nil {
+		return fmt.Errorf("LoadSubstitutionRulesFromJSON: %v", err)
+	}
+	configs := map[string]*TestNodeConfig{}
+	for name, v := range doc.TestNodeConfigs {
+		config := NewTestNodeConfig()
+		config.AsyncDelay = time.Duration(v.AsyncDelay) * time.Millisecond
+		config.PostScript = v.PostScript
+		if v.ReturnStatus != "" {
+			if err := config.ReturnStatus.FromString(v.ReturnStatus); err != nil {
+				return fmt.Errorf("LoadSubstitutionRulesFromJSON: invalid return_status of [%v]: %v", name, err)
+			}
+		}
+		if config.ReturnStatus == NodeStatus_IDLE || config.ReturnStatus == NodeStatus_RUNNING {
+			return fmt.Errorf("LoadSubstitutionRulesFromJSON: invalid return_status of [%v]: %v", name, v.ReturnStatus)
+		}
+		if config.PostScript != "" {
+			if _, err := ParseScript(config.PostScript); err != nil {
+				return fmt.Errorf("LoadSubstitutionRulesFromJSON: invalid post_script of [%v]: %v", name, err)
+			}
+		}
+		configs[name] = config
+	}
+	if len(doc.SubstitutionRules) == 0 {
+		return nil
+	}
+	// the rules are added in the order of the document, a map would lose it
+	dec := json.NewDecoder(bytes.NewReader(doc.SubstitutionRules))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return fmt.Errorf("LoadSubstitutionRulesFromJSON: SubstitutionRules must be an object")
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("LoadSubstitutionRulesFromJSON: %v", err)
+		}
+		filter, ok := t.(string)
+		if !ok {
+			return fmt.Errorf("LoadSubstitutionRulesFromJSON: invalid filter [%v]", t)
+		}
+		var rule string
+		if err := dec.Decode(&rule); err != nil {
+			return fmt.Errorf("LoadSubstitutionRulesFromJSON: invalid rule of [%v]: %v", filter, err)
+		}
+		if config, ok := configs[rule]; ok {
+			f.AddSubstitutionRule(filter, config)
+		} else {
+			f.AddSubstitutionRule(filter, rule)
+		}
+	}
+	return nil
+}
+
+// WildcardMatch tells if str matches the pattern, where * matches any sequence of characters
+// (including none) and ? matches any single character.
+func WildcardMatch(str, pattern string) bool {
+	s, p := 0, 0
+	star, match := -1, 0
+	for s < len(str) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == str[s]):
+			s++
+			p++
+		case p < len(pattern) && pattern[p] == '*':
+			star, match = p, s
+			p++
+		case star >= 0:
+			// backtrack: the last * matches one more character
+			p = star + 1
+			match++
+			s = match
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// PluginRegisterSymbol is the function that a plugin loaded by RegisterFromPlugin must export
+const PluginRegisterSymbol = "RegisterNodes"
+
+// RegisterFromPlugin loads a Go plugin (a package main built with -buildmode=plugin)
+// and calls its exported function:
+//
+//	func RegisterNodes(factory *core.BehaviorTreeFactory)
+//
+// The plugin must be built with the same version of Go and of this module as the executable.
+func (f *BehaviorTreeFactory) RegisterFromPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("RegisterFromPlugin: can't load [%v]; the plugin must be built with -buildmode=plugin, "+
+			"using the same version of Go and of the packages of the executable: %v", path, err)
+	}
+	symbol, err := p.Lookup(PluginRegisterSymbol)
+	if err != nil {
+		return fmt.Errorf("RegisterFromPlugin: the plugin [%v] doesn't export the function [%v]", path, PluginRegisterSymbol)
+	}
+	register, ok := symbol.(func(factory *BehaviorTreeFactory))
+	if !ok {
+		return fmt.Errorf("RegisterFromPlugin: the symbol [%v] of the plugin [%v] has type [%T], expected [func(*core.BehaviorTreeFactory)]",
+			PluginRegisterSymbol, path, symbol)
+	}
+	register(f)
+	return nil
+}
+
+func (f *BehaviorTreeFactory) RegisterScriptingEnum(name string, value int) {
+	f.scriptingEnums[name] = value
 }
 
 func (f *BehaviorTreeFactory) CreateTreeFromText(text string) (*Tree, error) {
@@ -192,7 +355,7 @@ func (f *BehaviorTreeFactory) CreateTreeFromText(text string) (*Tree, error) {
 			"This is NOT, probably, what you want to do.\n",
 			"You should probably use BehaviorTreeFactory::createTree, instead")
 	}
-	parser := NewXmlParser()
+	parser := NewXmlParser(f)
 	err := parser.LoadFromText(text)
 	if err != nil {
 		return nil, err
@@ -205,6 +368,7 @@ func (f *BehaviorTreeFactory) CreateTreeFromText(text string) (*Tree, error) {
 	for k, v := range f.Builders {
 		tree.manifests[k] = v.TreeNodeManifest
 	}
+	tree.builtinNodes = f.builtinNodes
 	return tree, nil
 }
 
@@ -219,7 +383,7 @@ func (f *BehaviorTreeFactory) CreateTreeFromFile(file_path string) (*Tree, error
 		)
 	}
 
-	parser := NewXmlParser()
+	parser := NewXmlParser(f)
 	err := parser.LoadFromFile(file_path)
 	if err != nil {
 		return nil, err
@@ -232,6 +396,7 @@ func (f *BehaviorTreeFactory) CreateTreeFromFile(file_path string) (*Tree, error
 	for k, v := range f.Builders {
 		tree.manifests[k] = v.TreeNodeManifest
 	}
+	tree.builtinNodes = f.builtinNodes
 	return tree, nil
 }
 func (f *BehaviorTreeFactory) CreateTree(treeName string) (*Tree, error) {
@@ -243,6 +408,7 @@ func (f *BehaviorTreeFactory) CreateTree(treeName string) (*Tree, error) {
 	for k, v := range f.Builders {
 		tree.manifests[k] = v.TreeNodeManifest
 	}
+	tree.builtinNodes = f.builtinNodes
 	return tree, nil
 }
 
@@ -258,22 +424,31 @@ func (f *BehaviorTreeFactory) RegisteredBehaviorTrees() []string {
 	return f.parser.RegisteredBehaviorTrees()
 }
 
+// addPorts adds the ports to the manifest of a registered node: the simple nodes
+// share the same Go type, so their ports can't be declared with SetPorts
+func (f *BehaviorTreeFactory) addPorts(ID string, ports ...*PortInfo) {
+	manifest := f.Builders[ID].TreeNodeManifest
+	for _, p := range ports {
+		manifest.Ports[p.Name] = p
+	}
+}
+
 func (f *BehaviorTreeFactory) RegisterSimpleCondition(
 	ID string, tickFunctor TickFunctor,
 	ports ...*PortInfo) {
-	SetPorts(nil, ports...)
 	f.RegisterNodeType(ID, NewSimpleConditionNode, tickFunctor)
+	f.addPorts(ID, ports...)
 }
 
 func (f *BehaviorTreeFactory) RegisterSimpleAction(ID string, tickFunctor TickFunctor,
 	ports ...*PortInfo) {
-	SetPorts(nil, ports...)
 	f.RegisterNodeType(ID, NewSimpleActionNode, tickFunctor)
+	f.addPorts(ID, ports...)
 }
 
 func (f *BehaviorTreeFactory) RegisterSimpleDecorator(
 	ID string, tickFunctor TickFunctor,
 	ports ...*PortInfo) {
-	SetPorts(nil, ports...)
 	f.RegisterNodeType(ID, NewSimpleDecoratorNode, tickFunctor)
+	f.addPorts(ID, ports...)
 }
@@ -35,6 +35,10 @@ func (n *ControlNode) AddChild(child ITreeNode) {
 	n.Children = append(n.Children, child)
 }
 
+func (n *ControlNode) GetChildren() []ITreeNode {
+	return n.Children
+}
+
 func (n *ControlNode) ResetChildren() {
 	for _, child := range n.Children {
 		if child.Status() == NodeStatus_RUNNING {
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StringConverter parses the string of a port into a value of its type
+type StringConverter func(str string) (any, error)
+
+var converters sync.Map // reflect.Type -> StringConverter
+
+// RegisterConverter registers the function used by ConvFromString, and so by the ports, to parse
+// the values of type T. It replaces the previous converter of T, builtin ones included, and it is
+// also used for the elements of the slices of T, written as "a;b;c".
+func RegisterConverter[T any](conv func(str string) (T, error)) {
+	converters.Store(typeOf[T](), StringConverter(func(str string) (any, error) {
+		return conv(str)
+	}))
+}
+
+// GetConverter returns the converter registered for the type t
+func GetConverter(t reflect.Type) (StringConverter, bool) {
+	conv, ok := converters.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return conv.(StringConverter), true
+}
+
+func init() {
+	// a number without unit is in milliseconds
+	RegisterConverter(func(str string) (time.Duration, error) {
+		if ms, err := ConvertInt64FromString(str); err == nil {
+			return time.Duration(ms) * time.Millisecond, nil
+		}
+		return time.ParseDuration(str)
+	})
+	RegisterConverter(func(str string) ([]string, error) {
+		if str == "" {
+			return nil, nil
+		}
+		return strings.Split(str, ";"), nil
+	})
+	RegisterConverter(func(str string) (status NodeStatus, err error) {
+		err = status.FromString(str)
+		return status, err
+	})
+}
+
+// convertSliceFromString parses the elements of a slice separated by semicolons, an empty string is a nil
+// slice; value is a pointer to the slice
+func convertSliceFromString(str string, value reflect.Value) error {
+	sliceType := value.Type().Elem()
+	res := reflect.Zero(sliceType)
+	if str != "" {
+		for _, part := range strings.Split(str, ";") {
+			elem := reflect.New(sliceType.Elem())
+			if err := ConvFromString(part, elem.Interface()); err != nil {
+				return err
+			}
+			res = reflect.Append(res, elem.Elem())
+		}
+	}
+	value.Elem().Set(res)
+	return nil
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvFromStringSlices(t *testing.T) {
+	// the previous content of the slice is replaced, whatever the type of the elements
+	ints, floats, strs := []int64{7}, []float64{7}, []string{"x"}
+	tests := []struct {
+		str   string
+		value any
+		want  any
+	}{
+		{"1;2;3", &ints, []int64{1, 2, 3}},
+		{"1.5;-2", &floats, []float64{1.5, -2}},
+		{"a;b", &strs, []string{"a", "b"}},
+		{"", &ints, []int64(nil)},
+		{"4", &[]int{}, []int{4}},
+	}
+	for _, tt := range tests {
+		if err := ConvFromString(tt.str, tt.value); err != nil {
+			t.Fatalf("[%v]: %v", tt.str, err)
+		}
+		if got := reflect.ValueOf(tt.value).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("[%v]: %#v, want %#v", tt.str, got, tt.want)
+		}
+	}
+
+	if err := ConvFromString("1;x", &ints); err == nil {
+		t.Error("no error for an invalid element")
+	}
+}
@@ -16,7 +16,7 @@ func NewDecoratorNode(name string, config *NodeConfig) *DecoratorNode {
 	}
 }
 func (n *DecoratorNode) NodeType() NodeType {
-	return NodeType_CONDITION
+	return NodeType_DECORATOR
 }
 
 func (n *DecoratorNode) SetChild(child ITreeNode) error {
@@ -35,6 +35,14 @@ func (n *DecoratorNode) Halt() {
 func (n *DecoratorNode) Child() ITreeNode {
 	return n.childNode
 }
+
+func (n *DecoratorNode) GetChildren() []ITreeNode {
+	if n.childNode == nil {
+		return nil
+	}
+	return []ITreeNode{n.childNode}
+}
+
 func (n *DecoratorNode) HaltChild() {
 	n.ResetChild()
 }
@@ -0,0 +1,92 @@
+package core_test
+
+import (
+	"github.com/gorustyt/go-behavior/core"
+	"testing"
+)
+
+// entryUpdatedStep is a tick of the tree: write tells if the entry is written before the tick
+type entryUpdatedStep struct {
+	write bool
+	// status returned by the child, if ticked
+	child      core.NodeStatus
+	wantStatus core.NodeStatus
+	wantTicked bool
+}
+
+// statusAction is an action that returns the status given by the test, RUNNING included
+type statusAction struct {
+	*core.ActionNodeBase
+	tick func() core.NodeStatus
+}
+
+func (n *statusAction) Tick() core.NodeStatus {
+	return n.tick()
+}
+
+func TestEntryUpdatedNodes(t *testing.T) {
+	tests := []struct {
+		id    string
+		steps []entryUpdatedStep
+	}{
+		{"SkipUnlessUpdated", []entryUpdatedStep{
+			{wantStatus: core.NodeStatus_SKIPPED},
+			{write: true, child: core.NodeStatus_SUCCESS, wantStatus: core.NodeStatus_SUCCESS, wantTicked: true},
+			{wantStatus: core.NodeStatus_SKIPPED},
+			{write: true, child: core.NodeStatus_FAILURE, wantStatus: core.NodeStatus_FAILURE, wantTicked: true},
+			{write: true, child: core.NodeStatus_RUNNING, wantStatus: core.NodeStatus_RUNNING, wantTicked: true},
+			// the running child is ticked again, even if the entry isn't updated
+			{child: core.NodeStatus_SUCCESS, wantStatus: core.NodeStatus_SUCCESS, wantTicked: true},
+			{wantStatus: core.NodeStatus_SKIPPED},
+		}},
+		{"WaitValueUpdate", []entryUpdatedStep{
+			{wantStatus: core.NodeStatus_RUNNING},
+			{write: true, child: core.NodeStatus_SUCCESS, wantStatus: core.NodeStatus_SUCCESS, wantTicked: true},
+			{wantStatus: core.NodeStatus_RUNNING},
+			{wantStatus: core.NodeStatus_RUNNING},
+			{write: true, child: core.NodeStatus_SUCCESS, wantStatus: core.NodeStatus_SUCCESS, wantTicked: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			f := newTestFactory()
+			var childStatus core.NodeStatus
+			ticked := false
+			err := core.Register(f, "Child", func(name string, cfg *core.NodeConfig) *statusAction {
+				return &statusAction{ActionNodeBase: core.NewActionNodeBase(name, cfg), tick: func() core.NodeStatus {
+					ticked = true
+					return childStatus
+				}}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree, err := f.CreateTreeFromText(`
+<root BTCPP_format="4">
+    <BehaviorTree ID="Main">
+        <` + tt.id + ` entry="{k}">
+            <Child/>
+        </` + tt.id + `>
+    </BehaviorTree>
+</root>`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bb := tree.Subtrees[0].Blackboard
+			for i, step := range tt.steps {
+				if step.write {
+					// the same value counts as an update
+					bb.Set("k", 1)
+				}
+				childStatus, ticked = step.child, false
+				status := tree.Root().ExecuteTick()
+				if status != step.wantStatus || ticked != step.wantTicked {
+					t.Fatalf("step %d: status %v, child ticked %v; want %v, %v", i, status, ticked, step.wantStatus, step.wantTicked)
+				}
+				if core.IsStatusCompleted(status) {
+					tree.Root().ResetStatus()
+				}
+			}
+		})
+	}
+}
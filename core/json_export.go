@@ -0,0 +1,438 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// JsonTypeField is the field added to the JSON objects of the registered types,
+// so that they can be converted back to the right Go type.
+const JsonTypeField = "__type"
+
+type jsonDefinition struct {
+	name     string
+	typ      reflect.Type
+	toJSON   func(value any) (map[string]any, error)
+	fromJSON func(j map[string]any) (any, error)
+}
+
+// JsonExporter converts the values of the blackboard to and from JSON.
+// Primitive types, slices and maps are supported out of the box; any other type
+// must be registered with RegisterJsonDefinition.
+type JsonExporter struct {
+	mutex  sync.RWMutex
+	byType map[reflect.Type]*jsonDefinition
+	byName map[string]*jsonDefinition
+}
+
+var jsonExporter = &JsonExporter{
+	byType: map[reflect.Type]*jsonDefinition{},
+	byName: map[string]*jsonDefinition{},
+}
+
+func GetJsonExporter() *JsonExporter {
+	return jsonExporter
+}
+
+// RegisterJsonDefinition adds the type T to the JsonExporter.
+// toJSON fills the JSON object from the value and fromJSON does the opposite;
+// if any of them is nil, encoding/json is used in its place.
+// In the JSON, the numbers passed to fromJSON are float64, as in encoding/json.
+func RegisterJsonDefinition[T any](toJSON func(j map[string]any, v *T), fromJSON func(j map[string]any, v *T) error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	def := &jsonDefinition{name: typ.String(), typ: typ}
+	def.toJSON = func(value any) (map[string]any, error) {
+		v := value.(T)
+		j := map[string]any{}
+		if toJSON != nil {
+			toJSON(j, &v)
+			return j, nil
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &j)
+		if err != nil {
+			return nil, fmt.Errorf("the type [%v] is not converted to a JSON object", typ)
+		}
+		return j, nil
+	}
+	def.fromJSON = func(j map[string]any) (any, error) {
+		var v T
+		if fromJSON != nil {
+			err := fromJSON(normalizeJsonNumbers(j).(map[string]any), &v)
+			return v, err
+		}
+		data, err := json.Marshal(j)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &v)
+		return v, err
+	}
+	jsonExporter.mutex.Lock()
+	defer jsonExporter.mutex.Unlock()
+	jsonExporter.byType[typ] = def
+	jsonExporter.byName[def.name] = def
+}
+
+func (e *JsonExporter) definition(typ reflect.Type) *jsonDefinition {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.byType[typ]
+}
+
+func (e *JsonExporter) definitionByName(name string) *jsonDefinition {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.byName[name]
+}
+
+// ToJson converts a value to a generic JSON value (nil, bool, number, string, []any or map[string]any),
+// that can be passed to json.Marshal.
+func (e *JsonExporter) ToJson(value any) (any, error) {
+	return e.toJson(reflect.ValueOf(value))
+}
+
+func (e *JsonExporter) toJson(rv reflect.Value) (any, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if def := e.definition(rv.Type()); def != nil {
+		j, err := def.toJSON(rv.Interface())
+		if err != nil {
+			return nil, err
+		}
+		j[JsonTypeField] = def.name
+		return j, nil
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		res := make([]any, rv.Len())
+		for i := range res {
+			v, err := e.toJson(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = v
+		}
+		return res, nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		res := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			v, err := e.toJson(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			res[fmt.Sprint(iter.Key().Interface())] = v
+		}
+		return res, nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return e.toJson(rv.Elem())
+	}
+	return nil, fmt.Errorf("the type [%v] is not registered in the JsonExporter", rv.Type())
+}
+
+func decodeJson(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	err := decoder.Decode(&v)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the value")
+	}
+	return v, nil
+}
+
+// normalizeJsonNumbers replaces the json.Number with float64, as encoding/json does by default
+func normalizeJsonNumbers(src any) any {
+	switch v := src.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			res[i] = normalizeJsonNumbers(item)
+		}
+		return res
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for k, item := range v {
+			res[k] = normalizeJsonNumbers(item)
+		}
+		return res
+	}
+	return src
+}
+
+// FromJson converts the JSON to a Go value, deducing its type:
+//   - the objects with the field "__type" become the registered type
+//   - the integers become int and the other numbers float64
+//   - the arrays whose items have all the same type T become []T, otherwise []any
+//   - the other objects become map[string]any
+func (e *JsonExporter) FromJson(data []byte) (any, error) {
+	src, err := decodeJson(data)
+	if err != nil {
+		return nil, err
+	}
+	return e.fromJson(src)
+}
+
+func (e *JsonExporter) fromJson(src any) (any, error) {
+	switch v := src.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(v.String()); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []any:
+		items := make([]any, len(v))
+		var itemType reflect.Type
+		sameType := len(v) > 0
+		for i, item := range v {
+			res, err := e.fromJson(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = res
+			if res == nil {
+				sameType = false
+				continue
+			}
+			if i == 0 {
+				itemType = reflect.TypeOf(res)
+			} else if itemType != reflect.TypeOf(res) {
+				sameType = false
+			}
+		}
+		if !sameType {
+			return items, nil
+		}
+		res := reflect.MakeSlice(reflect.SliceOf(itemType), len(items), len(items))
+		for i, item := range items {
+			res.Index(i).Set(reflect.ValueOf(item))
+		}
+		return res.Interface(), nil
+	case map[string]any:
+		if name, ok := v[JsonTypeField].(string); ok {
+			def := e.definitionByName(name)
+			if def == nil {
+				return nil, fmt.Errorf("the type [%v] is not registered in the JsonExporter", name)
+			}
+			return def.fromJSON(v)
+		}
+		res := make(map[string]any, len(v))
+		for k, item := range v {
+			converted, err := e.fromJson(item)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = converted
+		}
+		return res, nil
+	}
+	// nil, bool and string
+	return src, nil
+}
+
+// FromJsonTo converts the JSON to the type pointed by dst
+func (e *JsonExporter) FromJsonTo(data []byte, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("FromJsonTo needs a non-nil pointer, received [%T]", dst)
+	}
+	src, err := decodeJson(data)
+	if err != nil {
+		return err
+	}
+	return e.fromJsonTo(src, rv.Elem())
+}
+
+func (e *JsonExporter) fromJsonTo(src any, dst reflect.Value) error {
+	if def := e.definition(dst.Type()); def != nil {
+		j, ok := src.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected a JSON object for the type [%v]", def.name)
+		}
+		if name, ok := j[JsonTypeField]; ok && name != def.name {
+			return fmt.Errorf("the JSON contains the type [%v], but [%v] was expected", name, def.name)
+		}
+		v, err := def.fromJSON(j)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Slice:
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		items, ok := src.([]any)
+		if !ok {
+			return fmt.Errorf("expected a JSON array for the type [%v]", dst.Type())
+		}
+		res := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			err := e.fromJsonTo(item, res.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		dst.Set(res)
+		return nil
+	case reflect.Map:
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		j, ok := src.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected a JSON object for the type [%v]", dst.Type())
+		}
+		res := reflect.MakeMapWithSize(dst.Type(), len(j))
+		for k, item := range j {
+			key := reflect.New(dst.Type().Key()).Elem()
+			err := e.fromJsonTo(json.Number(k), key)
+			if dst.Type().Key().Kind() == reflect.String {
+				key.SetString(k)
+				err = nil
+			}
+			if err != nil {
+				return err
+			}
+			value := reflect.New(dst.Type().Elem()).Elem()
+			err = e.fromJsonTo(item, value)
+			if err != nil {
+				return err
+			}
+			res.SetMapIndex(key, value)
+		}
+		dst.Set(res)
+		return nil
+	case reflect.Pointer:
+		if src == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		value := reflect.New(dst.Type().Elem())
+		err := e.fromJsonTo(src, value.Elem())
+		if err != nil {
+			return err
+		}
+		dst.Set(value)
+		return nil
+	case reflect.Interface:
+		v, err := e.fromJson(src)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if !reflect.TypeOf(v).AssignableTo(dst.Type()) {
+			return fmt.Errorf("the JSON can't be converted to the type [%v]", dst.Type())
+		}
+		dst.Set(reflect.ValueOf(v))
+		return nil
+	}
+	// primitive types and the structs not registered
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst.Addr().Interface())
+}
+
+// ExportBlackboardToJSON returns a JSON object with the entries of the blackboard.
+// The entries whose type is not supported by the JsonExporter are skipped.
+func ExportBlackboardToJSON(bb *Blackboard) ([]byte, error) {
+	dest := map[string]any{}
+	keys := bb.GetKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry := bb.GetEntry(key)
+		if entry == nil {
+			continue
+		}
+		entry.entryMutex.Lock()
+		value, err := jsonExporter.ToJson(entry.Value)
+		entry.entryMutex.Unlock()
+		if err == nil {
+			dest[key] = value
+		}
+	}
+	return json.Marshal(dest)
+}
+
+// ImportBlackboardFromJSON sets the entries of the blackboard from a JSON object, as written
+// by ExportBlackboardToJSON. The entries that already exist keep their type.
+func ImportBlackboardFromJSON(bb *Blackboard, data []byte) error {
+	src, err := decodeJson(data)
+	if err != nil {
+		return err
+	}
+	j, ok := src.(map[string]any)
+	if !ok {
+		return fmt.Errorf("ImportBlackboardFromJSON: expected a JSON object")
+	}
+	keys := make([]string, 0, len(j))
+	for key := range j {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var value any
+		var prevType reflect.Type
+		if entry := bb.GetEntry(key); entry != nil {
+			entry.entryMutex.Lock()
+			prevType = reflect.TypeOf(entry.Value)
+			entry.entryMutex.Unlock()
+		}
+		if prevType != nil {
+			dst := reflect.New(prevType).Elem()
+			err = jsonExporter.fromJsonTo(j[key], dst)
+			value = dst.Interface()
+		} else {
+			value, err = jsonExporter.fromJson(j[key])
+		}
+		if err != nil {
+			return fmt.Errorf("ImportBlackboardFromJSON: entry [%v]: %v", key, err)
+		}
+		bb.Set(key, value)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"encoding/json"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"sync"
+	"testing"
+)
+
+// newTestFactory returns a factory with the built-in nodes
+func newTestFactory() *core.BehaviorTreeFactory {
+	f := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(f)
+	return f
+}
+
+// TestExportBlackboardWhileTicking dumps the blackboards while the tree is ticked: the subtree
+// caches the remapped entries of the parent. Run it with -race.
+func TestExportBlackboardWhileTicking(t *testing.T) {
+	tree, err := newTestFactory().CreateTreeFromText(`
+<root BTCPP_format="4" main_tree_to_execute="Main">
+    <BehaviorTree ID="Main">
+        <Sequence>
+            <Script code="a:=0; b:=0" />
+            <Repeat num_cycles="200">
+                <SubTree ID="Sub" x="{a}" _autoremap="true" />
+            </Repeat>
+        </Sequence>
+    </BehaviorTree>
+    <BehaviorTree ID="Sub">
+        <Script code="x+=1; b+=2; c:=b" />
+    </BehaviorTree>
+</root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	var wg, started sync.WaitGroup
+	// loop calls f until the tree is done
+	loop := func(f func() error) {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if err := f(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for _, subtree := range tree.Subtrees {
+		bb := subtree.Blackboard
+		loop(func() error {
+			_, err := core.ExportBlackboardToJSON(bb)
+			return err
+		})
+		loop(func() error {
+			bb.GetKeys()
+			return nil
+		})
+	}
+	started.Wait()
+	status := tree.TickWhileRunning()
+	close(done)
+	wg.Wait()
+
+	if status != core.NodeStatus_SUCCESS {
+		t.Fatalf("status %v, want SUCCESS", status)
+	}
+	data, err := core.ExportBlackboardToJSON(tree.Subtrees[0].Blackboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != 200.0 || got["b"] != 400.0 {
+		t.Fatalf("exported %s, want a=200 and b=400", data)
+	}
+}
@@ -1,23 +1,77 @@
 package core
 
 import (
+	"reflect"
 	"sync"
 )
 
 var (
-	ports sync.Map
+	portsMutex  sync.RWMutex
+	portsByType = map[reflect.Type][]*PortInfo{}
 )
 
+// nodeType returns the type used to store the ports of a node: &Node{} and Node{} are the same
+func nodeType(descType any) reflect.Type {
+	t := reflect.TypeOf(descType)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// SetPorts declares the ports of the node type of descType (e.g. &MyNode{}), usually in init().
+// A port with the same name of a port already declared replaces it.
 func SetPorts(descType any, ps ...*PortInfo) {
-	for _, v := range ps {
-		ports.Store(v.Name, v)
+	t := nodeType(descType)
+	if t == nil {
+		panic("SetPorts: the type of the node is required")
+	}
+	portsMutex.Lock()
+	defer portsMutex.Unlock()
+	ports := portsByType[t]
+	for _, p := range ps {
+		replaced := false
+		for i, v := range ports {
+			if v.Name == p.Name {
+				ports[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			ports = append(ports, p)
+		}
+	}
+	portsByType[t] = ports
+}
+
+// GetPorts returns the ports declared with SetPorts and with PortTag for the node type of descType
+func GetPorts(descType any) map[string]*PortInfo {
+	return getPortsOfType(nodeType(descType))
+}
+
+// getPortsOfType returns the ports declared with SetPorts and with PortTag for the struct type t
+func getPortsOfType(t reflect.Type) map[string]*PortInfo {
+	res := map[string]*PortInfo{}
+	portsMutex.RLock()
+	for _, p := range portsByType[t] {
+		res[p.Name] = p
+	}
+	portsMutex.RUnlock()
+	for _, p := range getTaggedPorts(t) {
+		res[p.info.Name] = p.info
 	}
+	return res
 }
 
-func GetPorts(name string) (res *PortInfo, ok bool) {
-	v, ok := ports.Load(name)
-	if !ok {
-		return res, ok
+// ProvidedPorts returns the ports of the node: those declared with SetPorts for its type,
+// those declared by its fields with PortTag and, if it implements IGetProvidedPorts,
+// those returned by GetProvidedPorts.
+func ProvidedPorts(node any) map[string]*PortInfo {
+	res := GetPorts(node)
+	if provider, ok := node.(IGetProvidedPorts); ok {
+		for name, p := range provider.GetProvidedPorts() {
+			res[name] = p
+		}
 	}
-	return v.(*PortInfo), ok
+	return res
 }
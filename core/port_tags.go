@@ -0,0 +1,171 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// PortTag is the key of the struct tags declaring the ports of a node as fields:
+//
+//	type MoveBaseAction struct {
+//		*core.StatefulActionNode
+//		Goal     Pose2D `bt:"in,goal,desc=target pose"`
+//		Attempts int    `bt:"in,,default=3"`
+//		Reached  bool   `bt:"out,reached"`
+//	}
+//
+// The first element is the direction (in, out or inout), the second is the name of the port,
+// the name of the field if empty. The options default= and desc= follow; desc must be the last one,
+// because the description may contain commas.
+//
+// The ports are part of the manifest of the node type. Before each Tick the input fields are filled
+// with the values of the ports, after Tick all the output fields are written with SetOutput.
+// The outputs are written after every Tick, RUNNING included, even if the field still holds
+// its zero value: a node that must not overwrite the entry uses SetOutput instead of a field.
+// Only the fields of interface type holding nil are skipped, because nil can't be written.
+const PortTag = "bt"
+
+// taggedPort is a port declared by a field of the node
+type taggedPort struct {
+	index []int
+	info  *PortInfo
+}
+
+var taggedPortsCache sync.Map // reflect.Type -> []*taggedPort
+
+// getTaggedPorts returns the ports declared with PortTag by the fields of the struct type t
+func getTaggedPorts(t reflect.Type) []*taggedPort {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if ports, ok := taggedPortsCache.Load(t); ok {
+		return ports.([]*taggedPort)
+	}
+	var ports []*taggedPort
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(PortTag)
+		if !ok || tag == "-" {
+			continue
+		}
+		if !field.IsExported() {
+			panic(fmt.Sprintf("the port field [%v.%v] must be exported", t.Name(), field.Name))
+		}
+		info, err := parsePortTag(field, tag)
+		if err != nil {
+			panic(fmt.Sprintf("invalid tag of the port field [%v.%v]: %v", t.Name(), field.Name, err))
+		}
+		ports = append(ports, &taggedPort{index: field.Index, info: info})
+	}
+	res, _ := taggedPortsCache.LoadOrStore(t, ports)
+	return res.([]*taggedPort)
+}
+
+func parsePortTag(field reflect.StructField, tag string) (*PortInfo, error) {
+	parts := strings.SplitN(tag, ",", 3)
+	var direction PortDirection
+	switch strings.TrimSpace(parts[0]) {
+	case "in":
+		direction = PortDirection_INPUT
+	case "out":
+		direction = PortDirection_OUTPUT
+	case "inout":
+		direction = PortDirection_INOUT
+	default:
+		return nil, fmt.Errorf("unknown direction [%v], expected in, out or inout", parts[0])
+	}
+	name := field.Name
+	if len(parts) > 1 && strings.TrimSpace(parts[1]) != "" {
+		name = strings.TrimSpace(parts[1])
+	}
+	if !IsAllowedPortName(name) {
+		return nil, fmt.Errorf("invalid port name [%v]", name)
+	}
+	info := NewPortInfo(direction, name)
+	info.typ = field.Type
+	if len(parts) < 3 {
+		return info, nil
+	}
+	options := parts[2]
+	for options != "" {
+		if desc, ok := strings.CutPrefix(options, "desc="); ok {
+			info.SetDescription(desc)
+			break
+		}
+		option, rest, _ := strings.Cut(options, ",")
+		options = rest
+		def, ok := strings.CutPrefix(option, "default=")
+		if !ok {
+			return nil, fmt.Errorf("unknown option [%v]", option)
+		}
+		value, err := convertValue(def, field.Type)
+		if err != nil {
+			return nil, err
+		}
+		info.SetDefaultValue(value)
+		info.defaultValueStr = def
+	}
+	return info, nil
+}
+
+// readTaggedPorts fills the input fields of the node with the values of their ports.
+// The fields of the ports that aren't assigned in the XML, or are remapped to an entry that
+// doesn't exist or hasn't been written yet, are left untouched: these inputs are optional.
+func (n *TreeNode) readTaggedPorts(node reflect.Value, ports []*taggedPort) {
+	for _, p := range ports {
+		if p.info.Direction() == PortDirection_OUTPUT {
+			continue
+		}
+		if _, ok := n.config.InputPorts[p.info.Name]; !ok {
+			continue
+		}
+		field := node.FieldByIndex(p.index)
+		value, err := n.getInputValue(p.info.Name, field.Type())
+		if errors.Is(err, ErrMissingKey) || errors.Is(err, ErrUninitializedEntry) {
+			continue
+		}
+		if err != nil {
+			panic(err)
+		}
+		field.Set(reflect.ValueOf(value))
+	}
+}
+
+// writeTaggedPorts writes the output fields of the node with SetOutput, after Tick,
+// whatever the returned status; the interface fields holding nil are skipped
+func (n *TreeNode) writeTaggedPorts(node reflect.Value, ports []*taggedPort) {
+	for _, p := range ports {
+		if p.info.Direction() == PortDirection_INPUT {
+			continue
+		}
+		if _, ok := n.config.OutputPorts[p.info.Name]; !ok {
+			continue
+		}
+		field := node.FieldByIndex(p.index)
+		if field.Kind() == reflect.Interface && field.IsNil() {
+			continue
+		}
+		n.SetOutput(p.info.Name, field.Interface())
+	}
+}
+
+// tickWithTaggedPorts calls the Tick of node, a pointer to a struct with tagged ports,
+// binding the fields to the ports
+func (n *TreeNode) tickWithTaggedPorts(node ITreeNode) NodeStatus {
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return node.Tick()
+	}
+	v = v.Elem()
+	ports := getTaggedPorts(v.Type())
+	if len(ports) == 0 {
+		return node.Tick()
+	}
+	n.readTaggedPorts(v, ports)
+	status := node.Tick()
+	n.writeTaggedPorts(v, ports)
+	return status
+}
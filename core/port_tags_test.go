@@ -0,0 +1,58 @@
+package core
+
+import (
+	"testing"
+)
+
+// outputTestNode returns RUNNING until Result is set
+type outputTestNode struct {
+	*ActionNodeBase
+	Result any `bt:"out,result"`
+	Count  int `bt:"out,count"`
+	ticks  int
+}
+
+func (n *outputTestNode) Tick() NodeStatus {
+	n.ticks++
+	if n.ticks < 2 {
+		return NodeStatus_RUNNING
+	}
+	n.Result, n.Count = "done", n.ticks
+	return NodeStatus_SUCCESS
+}
+
+func TestWriteTaggedPorts(t *testing.T) {
+	f := NewBehaviorTreeFactory()
+	err := Register(f, "Output", func(name string, cfg *NodeConfig) *outputTestNode {
+		return &outputTestNode{ActionNodeBase: NewActionNodeBase(name, cfg)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := f.CreateTreeFromText(`<root BTCPP_format="4"><BehaviorTree ID="Main">
+		<Output result="{result}" count="{count}"/>
+	</BehaviorTree></root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := tree.Subtrees[0].Blackboard
+	bb.Set("count", -1)
+
+	// RUNNING: the nil interface is skipped, the zero value is written anyway
+	if status := tree.TickOnce(); status != NodeStatus_RUNNING {
+		t.Fatalf("status %v, want RUNNING", status)
+	}
+	if result, err := BBGet[any](bb, "result"); err == nil {
+		t.Fatalf("nil output written as %v", result)
+	}
+	if count := BBGetOr(bb, "count", -1); count != 0 {
+		t.Fatalf("count %v, want 0", count)
+	}
+
+	if status := tree.TickOnce(); status != NodeStatus_SUCCESS {
+		t.Fatalf("status %v, want SUCCESS", status)
+	}
+	if result, count := BBGetOr(bb, "result", ""), BBGetOr(bb, "count", -1); result != "done" || count != 2 {
+		t.Fatalf("result %v, count %v; want done, 2", result, count)
+	}
+}
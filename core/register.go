@@ -0,0 +1,128 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrDuplicateID = errors.New("ID already registered")
+
+// RegisterOption changes the manifest of a node registered with Register
+type RegisterOption func(m *TreeNodeManifest)
+
+// WithPorts adds ports to the manifest, besides those declared for the type
+func WithPorts(ports ...*PortInfo) RegisterOption {
+	return func(m *TreeNodeManifest) {
+		for _, p := range ports {
+			m.Ports[p.Name] = p
+		}
+	}
+}
+
+// WithNodeType sets the type of the node, when it can't be derived from the embedded base node
+func WithNodeType(t NodeType) RegisterOption {
+	return func(m *TreeNodeManifest) {
+		m.Type = t
+	}
+}
+
+// WithDynamicPort sets the DynamicPort of the manifest, for the ports whose names aren't known in advance
+func WithDynamicPort(port func(name string) *PortInfo) RegisterOption {
+	return func(m *TreeNodeManifest) {
+		m.DynamicPort = port
+	}
+}
+
+// WithMetadata adds a key/value pair to the metadata of the manifest
+func WithMetadata(key, value string) RegisterOption {
+	return func(m *TreeNodeManifest) {
+		m.Metadata = append(m.Metadata, map[string]string{key: value})
+	}
+}
+
+// baseNodeTypes are the base nodes that give the type to the nodes embedding them
+var baseNodeTypes = map[reflect.Type]NodeType{
+	reflect.TypeOf(ActionNodeBase{}): NodeType_ACTION,
+	reflect.TypeOf(ConditionNode{}):  NodeType_CONDITION,
+	reflect.TypeOf(ControlNode{}):    NodeType_CONTROL,
+	reflect.TypeOf(DecoratorNode{}):  NodeType_DECORATOR,
+}
+
+// staticNodeType returns the type of the nearest base node embedded in the struct type t
+func staticNodeType(t reflect.Type) NodeType {
+	level := []reflect.Type{t}
+	visited := map[reflect.Type]bool{}
+	for len(level) > 0 {
+		var next []reflect.Type
+		for _, st := range level {
+			if st.Kind() != reflect.Struct || visited[st] {
+				continue
+			}
+			visited[st] = true
+			if nt, ok := baseNodeTypes[st]; ok {
+				return nt
+			}
+			for i := 0; i < st.NumField(); i++ {
+				if field := st.Field(i); field.Anonymous {
+					ft := field.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					next = append(next, ft)
+				}
+			}
+		}
+		level = next
+	}
+	return NodeType_UNDEFINED
+}
+
+// Register registers the node type T with a typed constructor. Unlike RegisterNodeType, the
+// constructor isn't called to build the manifest: the type of the node is derived from the embedded
+// base node, the ports are those declared with SetPorts and PortTag for T.
+// Ports that depend on the instance (IGetProvidedPorts) must be passed with WithPorts.
+//
+// It returns an error wrapping ErrDuplicateID if id is already registered (RegisterNodeType replaces it instead),
+// and an error if T doesn't embed a base node and WithNodeType isn't given.
+func Register[T ITreeNode](f *BehaviorTreeFactory, id string, cons func(name string, cfg *NodeConfig) T, opts ...RegisterOption) error {
+	if _, ok := f.Builders[id]; ok {
+		return fmt.Errorf("%w: [%v]", ErrDuplicateID, id)
+	}
+	if cons == nil {
+		return fmt.Errorf("Register [%v]: the constructor is required", id)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	manifest := &TreeNodeManifest{
+		Type:           staticNodeType(t),
+		RegistrationID: id,
+		Ports:          getPortsOfType(t),
+		Metadata:       make([]map[string]string, 0),
+	}
+	for _, opt := range opts {
+		opt(manifest)
+	}
+	if manifest.Type == NodeType_UNDEFINED {
+		return fmt.Errorf("Register [%v]: the type of the node can't be derived from [%v], use WithNodeType", id, t)
+	}
+	f.Builders[id] = &NodeBuilder{
+		Cons: func(name string, config *NodeConfig, args ...interface{}) ITreeNode {
+			return cons(name, config)
+		},
+		TreeNodeManifest: manifest,
+	}
+	return nil
+}
+
+// RegisterBuiltin registers the node like Register and adds it to the BuiltinNodes of the factory,
+// that WriteTreeNodesModelXML and WriteTreeToXML leave out of the models unless asked.
+func RegisterBuiltin[T ITreeNode](f *BehaviorTreeFactory, id string, cons func(name string, cfg *NodeConfig) T, opts ...RegisterOption) error {
+	if err := Register(f, id, cons, opts...); err != nil {
+		return err
+	}
+	f.builtinNodes[id] = struct{}{}
+	return nil
+}
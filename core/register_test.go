@@ -0,0 +1,49 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+type registerTestNode struct {
+	*SyncActionNode
+	Value int `bt:"in,value"`
+}
+
+func (n *registerTestNode) Tick() NodeStatus {
+	return NodeStatus_SUCCESS
+}
+
+func TestRegisterDuplicateID(t *testing.T) {
+	f := NewBehaviorTreeFactory()
+	calls := 0
+	cons := func(name string, cfg *NodeConfig) *registerTestNode {
+		calls++
+		return &registerTestNode{SyncActionNode: NewSyncActionNode(name, cfg)}
+	}
+	if err := RegisterBuiltin(f, "Node", cons); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Fatalf("Register called the constructor %d times", calls)
+	}
+	manifest := f.Builders["Node"].TreeNodeManifest
+	if manifest.Type != NodeType_ACTION || manifest.Ports["value"] == nil {
+		t.Fatalf("manifest %v %v, want an ACTION with the port [value]", manifest.Type, manifest.Ports)
+	}
+	if err := Register(f, "Node", cons); !errors.Is(err, ErrDuplicateID) {
+		t.Fatalf("Register of a duplicate ID: %v, want ErrDuplicateID", err)
+	}
+
+	// RegisterNodeType replaces the node, that isn't built-in anymore; a second time too
+	success := func(node ITreeNode, status ...NodeStatus) NodeStatus { return NodeStatus_SUCCESS }
+	for i := 0; i < 2; i++ {
+		f.RegisterSimpleAction("Node", success)
+	}
+	if _, ok := f.Builders["Node"].TreeNodeManifest.Ports["value"]; ok {
+		t.Fatal("RegisterNodeType didn't replace the node")
+	}
+	if len(f.BuiltinNodes()) != 0 {
+		t.Fatalf("BuiltinNodes() = %v, want none", f.BuiltinNodes())
+	}
+}
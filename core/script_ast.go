@@ -0,0 +1,482 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+type scriptEnv struct {
+	script     string
+	blackboard *Blackboard
+	enums      map[string]int
+}
+
+func (e *scriptEnv) errorf(column int, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if column > 0 {
+		return fmt.Errorf("error executing script [%v] at column %v: %v", e.script, column, msg)
+	}
+	return fmt.Errorf("error executing script [%v]: %v", e.script, msg)
+}
+
+type scriptExpr interface {
+	eval(env *scriptEnv) (any, error)
+}
+
+type scriptLiteral struct {
+	value any
+}
+
+func (e *scriptLiteral) eval(env *scriptEnv) (any, error) {
+	return e.value, nil
+}
+
+type scriptName struct {
+	name   string
+	column int
+}
+
+func (e *scriptName) eval(env *scriptEnv) (any, error) {
+	// search first in the enums table
+	if v, ok := env.enums[e.name]; ok {
+		return v, nil
+	}
+	if env.blackboard == nil {
+		return nil, env.errorf(e.column, "variable [%v] not found: the blackboard is not valid", e.name)
+	}
+	entry := env.blackboard.GetEntry(e.name)
+	if entry == nil {
+		return nil, env.errorf(e.column, "variable not found: [%v]", e.name)
+	}
+	entry.entryMutex.Lock()
+	defer entry.entryMutex.Unlock()
+	if entry.Value == nil {
+		return nil, env.errorf(e.column, "variable [%v] hasn't been initialized, yet", e.name)
+	}
+	return entry.Value, nil
+}
+
+type scriptUnary struct {
+	op      string
+	operand scriptExpr
+	column  int
+}
+
+func (e *scriptUnary) eval(env *scriptEnv) (any, error) {
+	v, err := e.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "!":
+		b, err := scriptToBool(v)
+		if err != nil {
+			return nil, env.errorf(e.column, "%v", err)
+		}
+		return !b, nil
+	case "-":
+		if i, ok := scriptToInt(v); ok {
+			return -i, nil
+		}
+		if f, ok := scriptToFloat(v); ok {
+			return -f, nil
+		}
+	case "~":
+		if i, ok := scriptToInt(v); ok {
+			return ^i, nil
+		}
+	}
+	return nil, env.errorf(e.column, "invalid operand for unary operator '%v'", e.op)
+}
+
+type scriptBinary struct {
+	op       string
+	lhs, rhs scriptExpr
+	column   int
+}
+
+func (e *scriptBinary) eval(env *scriptEnv) (any, error) {
+	lhs, err := e.lhs.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// logic operators are short-circuited
+	if e.op == "&&" || e.op == "||" {
+		l, err := scriptToBool(lhs)
+		if err != nil {
+			return nil, env.errorf(e.column, "%v", err)
+		}
+		if (e.op == "&&" && !l) || (e.op == "||" && l) {
+			return l, nil
+		}
+		rhs, err := e.rhs.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		r, err := scriptToBool(rhs)
+		if err != nil {
+			return nil, env.errorf(e.column, "%v", err)
+		}
+		return r, nil
+	}
+	rhs, err := e.rhs.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	res, err := scriptArithmetic(e.op, lhs, rhs)
+	if err != nil {
+		return nil, env.errorf(e.column, "%v", err)
+	}
+	return res, nil
+}
+
+func scriptArithmetic(op string, lhs, rhs any) (any, error) {
+	if op == ".." {
+		return scriptToString(lhs) + scriptToString(rhs), nil
+	}
+	ls, lIsString := lhs.(string)
+	rs, rIsString := rhs.(string)
+	if lIsString && rIsString {
+		if op == "+" {
+			return ls + rs, nil
+		}
+		return nil, fmt.Errorf("operator '%v' not permitted between strings", op)
+	}
+	// a string mixed with a number is converted, if possible (values coming from XML are strings)
+	if lIsString {
+		if v, ok := scriptParseNumber(ls); ok {
+			lhs = v
+		}
+	}
+	if rIsString {
+		if v, ok := scriptParseNumber(rs); ok {
+			rhs = v
+		}
+	}
+	li, lIsInt := scriptToInt(lhs)
+	ri, rIsInt := scriptToInt(rhs)
+	if lIsInt && rIsInt {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "/":
+			if ri == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			if li%ri == 0 {
+				return li / ri, nil
+			}
+			return float64(li) / float64(ri), nil
+		case "&":
+			return li & ri, nil
+		case "|":
+			return li | ri, nil
+		case "^":
+			return li ^ ri, nil
+		}
+	}
+	lf, lok := scriptToFloat(lhs)
+	rf, rok := scriptToFloat(rhs)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator '%v' not permitted between [%v] and [%v]", op, lhs, rhs)
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		return lf / rf, nil
+	}
+	return nil, fmt.Errorf("bitwise operator '%v' requires integer operands", op)
+}
+
+type scriptComparison struct {
+	ops      []string
+	columns  []int
+	operands []scriptExpr
+}
+
+func (e *scriptComparison) eval(env *scriptEnv) (any, error) {
+	lhs, err := e.operands[0].eval(env)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range e.ops {
+		rhs, err := e.operands[i+1].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		res, err := scriptCompare(op, lhs, rhs)
+		if err != nil {
+			return nil, env.errorf(e.columns[i], "%v", err)
+		}
+		if !res {
+			return false, nil
+		}
+		lhs = rhs
+	}
+	return true, nil
+}
+
+func scriptCompare(op string, lhs, rhs any) (bool, error) {
+	var cmp int
+	ls, lIsString := lhs.(string)
+	rs, rIsString := rhs.(string)
+	lf, lIsNumber := scriptToFloat(lhs)
+	rf, rIsNumber := scriptToFloat(rhs)
+	switch {
+	case lIsString && rIsString:
+		cmp = compareOrdered(ls, rs)
+	case lIsNumber && rIsNumber:
+		cmp = compareOrdered(lf, rf)
+	default:
+		// a string compared with a number is converted, if possible
+		if lIsString {
+			lf, lIsNumber = scriptStringToFloat(ls)
+		}
+		if rIsString {
+			rf, rIsNumber = scriptStringToFloat(rs)
+		}
+		if lIsNumber && rIsNumber {
+			cmp = compareOrdered(lf, rf)
+		} else if op == "==" || op == "!=" {
+			cmp = 1
+			if lhs != nil && reflect.TypeOf(lhs) == reflect.TypeOf(rhs) && reflect.TypeOf(lhs).Comparable() && lhs == rhs {
+				cmp = 0
+			}
+		} else {
+			return false, fmt.Errorf("can't compare [%v] and [%v] with operator '%v'", lhs, rhs, op)
+		}
+	}
+	switch op {
+	case "==":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return false, fmt.Errorf("unknown comparison operator '%v'", op)
+}
+
+func compareOrdered[T int | float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+type scriptTernary struct {
+	cond, then, otherwise scriptExpr
+}
+
+func (e *scriptTernary) eval(env *scriptEnv) (any, error) {
+	c, err := e.cond.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, err := scriptToBool(c)
+	if err != nil {
+		return nil, env.errorf(0, "%v", err)
+	}
+	if b {
+		return e.then.eval(env)
+	}
+	return e.otherwise.eval(env)
+}
+
+type scriptAssignment struct {
+	name   string
+	op     string
+	value  scriptExpr
+	column int
+}
+
+func (e *scriptAssignment) eval(env *scriptEnv) (any, error) {
+	if _, ok := env.enums[e.name]; ok {
+		return nil, env.errorf(e.column, "can't assign a value to the enum [%v]", e.name)
+	}
+	if env.blackboard == nil {
+		return nil, env.errorf(e.column, "can't assign [%v]: the blackboard is not valid", e.name)
+	}
+	value, err := e.value.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	entry := env.blackboard.GetEntry(e.name)
+	if entry == nil && e.op != ":=" {
+		return nil, env.errorf(e.column, "variable [%v] doesn't exist; use ':=' to create a new one", e.name)
+	}
+	var prev any
+	if entry != nil {
+		entry.entryMutex.Lock()
+		prev = entry.Value
+		entry.entryMutex.Unlock()
+	}
+	if e.op != ":=" && e.op != "=" {
+		if prev == nil {
+			return nil, env.errorf(e.column, "variable [%v] hasn't been initialized, yet", e.name)
+		}
+		value, err = scriptArithmetic(e.op[:1], prev, value)
+		if err != nil {
+			return nil, env.errorf(e.column, "%v", err)
+		}
+	}
+	if prev != nil {
+		// once declared, the type of an entry shall not change
+		value, err = scriptConvertTo(value, reflect.TypeOf(prev))
+		if err != nil {
+			return nil, env.errorf(e.column, "can't assign to [%v]: %v", e.name, err)
+		}
+	}
+	env.blackboard.Set(e.name, value)
+	return value, nil
+}
+
+// scriptConvertTo converts the result of an expression into the type already stored in the blackboard
+func scriptConvertTo(value any, dst reflect.Type) (any, error) {
+	if reflect.TypeOf(value) == dst {
+		return value, nil
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(scriptToString(value)).Convert(dst).Interface(), nil
+	case reflect.Bool:
+		b, err := scriptToBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(b).Convert(dst).Interface(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := scriptToFloat(value)
+		if s, isString := value.(string); isString {
+			f, ok = scriptStringToFloat(s)
+		}
+		if !ok {
+			return nil, fmt.Errorf("[%v] is not a number", value)
+		}
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("floating point [%v] truncated to integer", f)
+		}
+		if dst.Kind() >= reflect.Uint && dst.Kind() <= reflect.Uint64 && f < 0 {
+			return nil, fmt.Errorf("negative value [%v] assigned to unsigned integer", f)
+		}
+		v := reflect.New(dst).Elem()
+		if (v.CanInt() && v.OverflowInt(int64(f))) || (v.CanUint() && v.OverflowUint(uint64(f))) {
+			return nil, fmt.Errorf("value [%v] overflows %v", value, dst)
+		}
+		return reflect.ValueOf(int64(f)).Convert(dst).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		f, ok := scriptToFloat(value)
+		if s, isString := value.(string); isString {
+			f, ok = scriptStringToFloat(s)
+		}
+		if !ok {
+			return nil, fmt.Errorf("[%v] is not a number", value)
+		}
+		return reflect.ValueOf(f).Convert(dst).Interface(), nil
+	}
+	// custom types can still be assigned from a string, if they implement IFromStr
+	if s, ok := value.(string); ok {
+		ptr := reflect.New(dst)
+		if err := ConvFromString(s, ptr.Interface()); err == nil {
+			return ptr.Elem().Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("type [%v] can't be converted to [%v]", reflect.TypeOf(value), dst)
+}
+
+func scriptToInt(v any) (int, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint()), true
+	case reflect.Bool:
+		if rv.Bool() {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func scriptToFloat(v any) (float64, bool) {
+	if i, ok := scriptToInt(v); ok {
+		return float64(i), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func scriptParseNumber(s string) (any, bool) {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	return nil, false
+}
+
+func scriptStringToFloat(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil
+}
+
+func scriptToString(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case fmt.Stringer:
+		return t.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func scriptToBool(v any) (bool, error) {
+	switch t := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return t, nil
+	case string:
+		switch t {
+		case "", "0", "false", "FALSE", "False":
+			return false, nil
+		case "1", "true", "TRUE", "True":
+			return true, nil
+		}
+		return false, fmt.Errorf("string [%v] can't be converted to bool", t)
+	}
+	if f, ok := scriptToFloat(v); ok {
+		return f != 0, nil
+	}
+	return false, fmt.Errorf("type [%v] can't be converted to bool", reflect.TypeOf(v))
+}
@@ -0,0 +1,409 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScriptError is returned when a script can't be parsed. Column is 1-based.
+type ScriptError struct {
+	Script string
+	Column int
+	Msg    string
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("error parsing script [%v] at column %v: %v", e.Script, e.Column, e.Msg)
+}
+
+type scriptTokenKind int
+
+const (
+	scriptToken_EOF scriptTokenKind = iota
+	scriptToken_INTEGER
+	scriptToken_REAL
+	scriptToken_STRING
+	scriptToken_IDENT
+	scriptToken_OP
+)
+
+type scriptToken struct {
+	kind   scriptTokenKind
+	text   string
+	column int
+}
+
+// longest operators first, so that the lexer is greedy
+var scriptOperators = []string{
+	":=", "+=", "-=", "*=", "/=", "==", "!=", "<=", ">=", "&&", "||", "..",
+	"=", "<", ">", "+", "-", "*", "/", "!", "~", "&", "|", "^", "?", ":", "(", ")", ";",
+}
+
+func isScriptIdentStart(c uint8) bool {
+	return IsAlpha(c) || c == '_'
+}
+
+func isScriptDigit(c uint8) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isScriptHexDigit(c uint8) bool {
+	return isScriptDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func tokenizeScript(script string) (tokens []scriptToken, err error) {
+	i := 0
+	for i < len(script) {
+		c := script[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case isScriptDigit(c) || (c == '.' && i+1 < len(script) && isScriptDigit(script[i+1])):
+			kind := scriptToken_INTEGER
+			if c == '0' && i+1 < len(script) && (script[i+1] == 'x' || script[i+1] == 'X') {
+				i += 2
+				for i < len(script) && isScriptHexDigit(script[i]) {
+					i++
+				}
+				if i == start+2 {
+					return nil, &ScriptError{Script: script, Column: start + 1, Msg: "invalid hexadecimal number"}
+				}
+			} else {
+				for i < len(script) && isScriptDigit(script[i]) {
+					i++
+				}
+				// a single dot belongs to the number, two dots are the concat operator
+				if i < len(script) && script[i] == '.' && !(i+1 < len(script) && script[i+1] == '.') {
+					kind = scriptToken_REAL
+					i++
+					for i < len(script) && isScriptDigit(script[i]) {
+						i++
+					}
+				}
+				if i < len(script) && (script[i] == 'e' || script[i] == 'E') {
+					kind = scriptToken_REAL
+					i++
+					if i < len(script) && (script[i] == '+' || script[i] == '-') {
+						i++
+					}
+					expStart := i
+					for i < len(script) && isScriptDigit(script[i]) {
+						i++
+					}
+					if i == expStart {
+						return nil, &ScriptError{Script: script, Column: start + 1, Msg: "invalid exponent in number"}
+					}
+				}
+			}
+			if i < len(script) && isScriptIdentStart(script[i]) {
+				return nil, &ScriptError{Script: script, Column: i + 1, Msg: "unexpected character after number"}
+			}
+			tokens = append(tokens, scriptToken{kind: kind, text: script[start:i], column: start + 1})
+			continue
+		case c == '\'' || c == '"':
+			i++
+			for i < len(script) && script[i] != c {
+				i++
+			}
+			if i >= len(script) {
+				return nil, &ScriptError{Script: script, Column: start + 1, Msg: "unterminated string literal"}
+			}
+			tokens = append(tokens, scriptToken{kind: scriptToken_STRING, text: script[start+1 : i], column: start + 1})
+			i++
+			continue
+		case isScriptIdentStart(c):
+			i++
+			for i < len(script) && (isScriptIdentStart(script[i]) || isScriptDigit(script[i])) {
+				i++
+			}
+			tokens = append(tokens, scriptToken{kind: scriptToken_IDENT, text: script[start:i], column: start + 1})
+			continue
+		}
+		matched := false
+		for _, op := range scriptOperators {
+			if strings.HasPrefix(script[i:], op) {
+				tokens = append(tokens, scriptToken{kind: scriptToken_OP, text: op, column: start + 1})
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, &ScriptError{Script: script, Column: start + 1, Msg: fmt.Sprintf("unexpected character '%c'", c)}
+		}
+	}
+	tokens = append(tokens, scriptToken{kind: scriptToken_EOF, column: len(script) + 1})
+	return tokens, nil
+}
+
+type scriptParser struct {
+	script string
+	tokens []scriptToken
+	pos    int
+}
+
+func (p *scriptParser) peek() scriptToken {
+	return p.tokens[p.pos]
+}
+
+func (p *scriptParser) next() scriptToken {
+	t := p.tokens[p.pos]
+	if t.kind != scriptToken_EOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *scriptParser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != scriptToken_OP {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *scriptParser) errorf(t scriptToken, format string, args ...any) error {
+	return &ScriptError{Script: p.script, Column: t.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *scriptParser) parseStatements() (res []scriptExpr, err error) {
+	for {
+		for p.isOp(";") {
+			p.next()
+		}
+		if p.peek().kind == scriptToken_EOF {
+			break
+		}
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, expr)
+		if t := p.peek(); t.kind != scriptToken_EOF && !p.isOp(";") {
+			return nil, p.errorf(t, "expected ';' or end of script, found '%v'", t.text)
+		}
+	}
+	if len(res) == 0 {
+		return nil, &ScriptError{Script: p.script, Column: 1, Msg: "empty script"}
+	}
+	return res, nil
+}
+
+func (p *scriptParser) parseExpr() (scriptExpr, error) {
+	// assignment is right associative and only allowed on a plain name
+	if t := p.peek(); t.kind == scriptToken_IDENT && p.tokens[p.pos+1].kind == scriptToken_OP {
+		switch op := p.tokens[p.pos+1].text; op {
+		case ":=", "=", "+=", "-=", "*=", "/=":
+			p.pos += 2
+			value, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return &scriptAssignment{name: t.text, op: op, value: value, column: t.column}, nil
+		}
+	}
+	return p.parseTernary()
+}
+
+func (p *scriptParser) parseTernary() (scriptExpr, error) {
+	cond, err := p.parseLogicOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp("?") {
+		return cond, nil
+	}
+	p.next()
+	then, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isOp(":") {
+		return nil, p.errorf(p.peek(), "expected ':' in ternary operator")
+	}
+	p.next()
+	otherwise, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &scriptTernary{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// parseBinary parses a left associative chain of the given operators
+func (p *scriptParser) parseBinary(operand func() (scriptExpr, error), ops ...string) (scriptExpr, error) {
+	lhs, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(ops...) {
+		t := p.next()
+		rhs, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		lhs = &scriptBinary{op: t.text, lhs: lhs, rhs: rhs, column: t.column}
+	}
+	return lhs, nil
+}
+
+func (p *scriptParser) parseLogicOr() (scriptExpr, error) {
+	return p.parseBinary(p.parseLogicAnd, "||")
+}
+
+func (p *scriptParser) parseLogicAnd() (scriptExpr, error) {
+	return p.parseBinary(p.parseBitOr, "&&")
+}
+
+func (p *scriptParser) parseBitOr() (scriptExpr, error) {
+	return p.parseBinary(p.parseBitXor, "|")
+}
+
+func (p *scriptParser) parseBitXor() (scriptExpr, error) {
+	return p.parseBinary(p.parseBitAnd, "^")
+}
+
+func (p *scriptParser) parseBitAnd() (scriptExpr, error) {
+	return p.parseBinary(p.parseComparison, "&")
+}
+
+// parseComparison accepts chained comparisons: "a < b < c" means "a < b && b < c"
+func (p *scriptParser) parseComparison() (scriptExpr, error) {
+	first, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	cmp := &scriptComparison{operands: []scriptExpr{first}}
+	for p.isOp("==", "!=", "<", "<=", ">", ">=") {
+		t := p.next()
+		rhs, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		cmp.ops = append(cmp.ops, t.text)
+		cmp.columns = append(cmp.columns, t.column)
+		cmp.operands = append(cmp.operands, rhs)
+	}
+	if len(cmp.ops) == 0 {
+		return first, nil
+	}
+	return cmp, nil
+}
+
+func (p *scriptParser) parseAdditive() (scriptExpr, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-", "..")
+}
+
+func (p *scriptParser) parseMultiplicative() (scriptExpr, error) {
+	return p.parseBinary(p.parseUnary, "*", "/")
+}
+
+func (p *scriptParser) parseUnary() (scriptExpr, error) {
+	if p.isOp("-", "!", "~") {
+		t := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &scriptUnary{op: t.text, operand: operand, column: t.column}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *scriptParser) parsePrimary() (scriptExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case scriptToken_INTEGER:
+		v, err := strconv.ParseInt(t.text, 0, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid integer [%v]", t.text)
+		}
+		return &scriptLiteral{value: int(v)}, nil
+	case scriptToken_REAL:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number [%v]", t.text)
+		}
+		return &scriptLiteral{value: v}, nil
+	case scriptToken_STRING:
+		return &scriptLiteral{value: t.text}, nil
+	case scriptToken_IDENT:
+		switch t.text {
+		case "true":
+			return &scriptLiteral{value: true}, nil
+		case "false":
+			return &scriptLiteral{value: false}, nil
+		}
+		return &scriptName{name: t.text, column: t.column}, nil
+	case scriptToken_OP:
+		if t.text == "(" {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.isOp(")") {
+				return nil, p.errorf(p.peek(), "expected ')'")
+			}
+			p.next()
+			return expr, nil
+		}
+		return nil, p.errorf(t, "unexpected operator '%v'", t.text)
+	}
+	return nil, p.errorf(t, "unexpected end of script")
+}
+
+func parseScriptStatements(script string) ([]scriptExpr, error) {
+	tokens, err := tokenizeScript(script)
+	if err != nil {
+		return nil, err
+	}
+	p := &scriptParser{script: script, tokens: tokens}
+	return p.parseStatements()
+}
+
+// ValidateScript returns a *ScriptError if the script can't be parsed
+func ValidateScript(script string) error {
+	_, err := parseScriptStatements(script)
+	return err
+}
+
+// ParseScript compiles a script of the BT.CPP scripting language.
+// The returned function must be called with the *Blackboard and the enums (map[string]int)
+// of the node; it returns the value of the last statement, converted to bool.
+// Errors at runtime (unknown variable, invalid operation) panic, like any other
+// misconfiguration of the tree.
+func ParseScript(script string) (ScriptFunction, error) {
+	statements, err := parseScriptStatements(script)
+	if err != nil {
+		return nil, err
+	}
+	return func(args ...interface{}) bool {
+		env := &scriptEnv{script: script}
+		if len(args) > 0 {
+			env.blackboard, _ = args[0].(*Blackboard)
+		}
+		if len(args) > 1 {
+			env.enums, _ = args[1].(map[string]int)
+		}
+		var result any
+		for _, v := range statements {
+			result, err = v.eval(env)
+			if err != nil {
+				panic(err)
+			}
+		}
+		res, err := scriptToBool(result)
+		if err != nil {
+			// values that aren't booleans, like a plain string, are simply "true"
+			return result != nil
+		}
+		return res
+	}, nil
+}
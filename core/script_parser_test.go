@@ -0,0 +1,173 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+var testScriptEnums = map[string]int{"RED": 1, "GREEN": 2, "BLUE": 3}
+
+// evalScript runs the script and returns the value of the blackboard entry "r"
+func evalScript(t *testing.T, bb *Blackboard, script string) any {
+	t.Helper()
+	f, err := ParseScript(script)
+	if err != nil {
+		t.Fatalf("ParseScript(%q): %v", script, err)
+	}
+	f(bb, testScriptEnums)
+	entry := bb.GetEntry("r")
+	if entry == nil {
+		t.Fatalf("%q didn't write [r]", script)
+	}
+	return entry.Value
+}
+
+// scriptPanics reports whether running the script panics
+func scriptPanics(bb *Blackboard, script string) (panicked bool) {
+	f, err := ParseScript(script)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		panicked = recover() != nil
+	}()
+	f(bb, testScriptEnums)
+	return false
+}
+
+func TestScriptOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		expr string
+		want any
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 3 / 2", 2},
+		{"-2 * 3", -6},
+		{"2 * 3 + 4 * 5", 26},
+		{"1 + 2 == 3", true},
+		{"(1 < 2) == true", true},
+		{"1 < 2 == 2", true},
+		{"1 | 2 & 3", 3},
+		{"6 ^ 3 & 1", 7},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"!false && true", true},
+		{"1 < 2 < 3", true},
+		{"3 > 2 > 1", true},
+		{"1 < 3 < 2", false},
+		{"1 + 1 > 1 ? 'yes' : 'no'", "yes"},
+		{"false ? 1 : true ? 2 : 3", 2},
+		{"1 + 2 .. 'b'", "3b"},
+		{"'a' .. (1 + 2)", "a3"},
+		{"0x10 + 1", 17},
+		{"~0", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			bb := NewBlackboard(nil)
+			if got := evalScript(t, bb, "r := "+tt.expr); got != tt.want {
+				t.Errorf("%v = %v (%T), want %v (%T)", tt.expr, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestScriptAssignment(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  string
+		script string
+		want   any
+		panics bool
+	}{
+		{name: "declare", script: "r := 1", want: 1},
+		{name: "assign existing", setup: "r := 1", script: "r = 5", want: 5},
+		{name: "assign missing", script: "r = 1", panics: true},
+		{name: "redeclare", setup: "r := 1", script: "r := 2", want: 2},
+		{name: "compound", setup: "r := 4", script: "r += 2; r *= 3", want: 18},
+		{name: "compound missing", script: "r += 1", panics: true},
+		{name: "keep type", setup: "r := 1.5", script: "r = 2", want: 2.0},
+		{name: "change type", setup: "r := 1", script: "r = 'text'", panics: true},
+		{name: "truncate real", setup: "r := 1", script: "r := 1.5", panics: true},
+		{name: "string from number", setup: "r := 'a'", script: "r = 3", want: "3"},
+		{name: "assign enum", script: "RED := 4", panics: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bb := NewBlackboard(nil)
+			if tt.setup != "" {
+				evalScript(t, bb, tt.setup)
+			}
+			if tt.panics {
+				if !scriptPanics(bb, tt.script) {
+					t.Fatalf("%q didn't fail", tt.script)
+				}
+				return
+			}
+			if got := evalScript(t, bb, tt.script); got != tt.want {
+				t.Errorf("%q: r = %v (%T), want %v (%T)", tt.script, got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestScriptEnums(t *testing.T) {
+	tests := []struct {
+		script string
+		want   any
+	}{
+		{"r := GREEN", 2},
+		{"r := BLUE - RED", 2},
+		{"r := RED == 1", true},
+		{"color := BLUE; r := color == BLUE", true},
+		{"color := GREEN; r := color != BLUE ? 'other' : 'blue'", "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			bb := NewBlackboard(nil)
+			if got := evalScript(t, bb, tt.script); got != tt.want {
+				t.Errorf("r = %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+
+	// the enums shadow the entries of the blackboard
+	bb := NewBlackboard(nil)
+	bb.Set("RED", 100)
+	if got := evalScript(t, bb, "r := RED"); got != 1 {
+		t.Errorf("r = %v, want the enum value 1", got)
+	}
+}
+
+func TestScriptErrorColumn(t *testing.T) {
+	tests := []struct {
+		script string
+		column int
+	}{
+		{"A := (3 + ", 11},
+		{"A := 3 B", 8},
+		{"A := 'unterminated", 6},
+		{"A := 3 $ 4", 8},
+		{"A :=", 5},
+		{"(1 + 2", 7},
+		{"A := 1 ? 2", 11},
+		{"x := 1; y := )", 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.script, func(t *testing.T) {
+			err := ValidateScript(tt.script)
+			var se *ScriptError
+			if !errors.As(err, &se) {
+				t.Fatalf("got %v, want a *ScriptError", err)
+			}
+			if se.Column != tt.column {
+				t.Errorf("column = %v, want %v (%v)", se.Column, tt.column, se)
+			}
+			if se.Script != tt.script {
+				t.Errorf("script = %q, want %q", se.Script, tt.script)
+			}
+		})
+	}
+}
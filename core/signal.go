@@ -1,21 +1,55 @@
 package core
 
+import "sync"
+
 type CallableFunction func(args ...any)
 
 type Signal struct {
-	subscribers_ []CallableFunction
+	mutex        sync.Mutex
+	nextId       uint64
+	subscribers_ []*Subscriber
+}
+
+// Subscriber is the handle returned by Signal.Subscribe, used to stop receiving notifications
+type Subscriber struct {
+	signal *Signal
+	id     uint64
+	fn     CallableFunction
 }
 
 func NewSignal() *Signal {
 	return &Signal{}
 }
-func (c *Signal) Subscribe(fn CallableFunction) CallableFunction {
-	c.subscribers_ = append(c.subscribers_, fn)
-	return fn
+
+func (c *Signal) Subscribe(fn CallableFunction) *Subscriber {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.nextId++
+	s := &Subscriber{signal: c, id: c.nextId, fn: fn}
+	c.subscribers_ = append(c.subscribers_, s)
+	return s
+}
+
+// Unsubscribe removes the callback from the signal. It is safe to call it more than once,
+// even from inside the callback itself.
+func (s *Subscriber) Unsubscribe() {
+	c := s.signal
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, v := range c.subscribers_ {
+		if v.id == s.id {
+			c.subscribers_ = append(c.subscribers_[:i:i], c.subscribers_[i+1:]...)
+			return
+		}
+	}
 }
 
 func (c *Signal) Notify(args ...any) {
-	for _, v := range c.subscribers_ {
-		v(args...)
+	// the callbacks are invoked without holding the lock, so that they can (un)subscribe
+	c.mutex.Lock()
+	subscribers := c.subscribers_
+	c.mutex.Unlock()
+	for _, v := range subscribers {
+		v.fn(args...)
 	}
 }
@@ -15,7 +15,11 @@ func NewSortMap[T1 cmp.Ordered, T2 any]() *SortMap[T1, T2] {
 }
 
 func (s *SortMap[T1, T2]) Set(key T1, value T2) {
+	_, exist := s.data[key]
 	s.data[key] = value
+	if exist {
+		return
+	}
 	s.index = append(s.index, key)
 	sort.Slice(s.index, func(i, j int) bool {
 		return s.index[i] < s.index[j]
@@ -29,6 +33,10 @@ func (s *SortMap[T1, T2]) Front() (value T2, ok bool) {
 	return s.Get(s.index[0])
 }
 
+func (s *SortMap[T1, T2]) Len() int {
+	return len(s.index)
+}
+
 func (s *SortMap[T1, T2]) Get(key T1) (value T2, ok bool) {
 	v, ok := s.data[key]
 	return v, ok
@@ -0,0 +1,95 @@
+package core_test
+
+import (
+	"github.com/gorustyt/go-behavior/core"
+	"testing"
+)
+
+const substitutionTestTree = `
+<root BTCPP_format="4">
+    <BehaviorTree ID="Main">
+        <Sequence>
+            <AlwaysSuccess name="action_exact"/>
+            <AlwaysSuccess name="action_other"/>
+            <AlwaysFailure name="by_id"/>
+            <AlwaysSuccess name="unmatched"/>
+        </Sequence>
+    </BehaviorTree>
+</root>`
+
+// substitutedConfigs returns the configs of the TestNodes created by the substitution rules, by name
+func substitutedConfigs(t *testing.T, f *core.BehaviorTreeFactory) map[string]*core.TestNodeConfig {
+	t.Helper()
+	tree, err := f.CreateTreeFromText(substitutionTestTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := map[string]*core.TestNodeConfig{}
+	for _, node := range tree.Subtrees[0].Nodes {
+		if testNode, ok := node.(*core.TestNode); ok {
+			res[node.Name()] = testNode.TestConfig
+		}
+	}
+	return res
+}
+
+func TestSubstitutionRulePrecedence(t *testing.T) {
+	f := newTestFactory()
+	wildcard, exact, byID := core.NewTestNodeConfig(), core.NewTestNodeConfig(), core.NewTestNodeConfig()
+	// the wildcards are added first, but the name and the ID win
+	f.AddSubstitutionRule("action_*", wildcard)
+	f.AddSubstitutionRule("*y_id", wildcard)
+	f.AddSubstitutionRule("action_exact", exact)
+	f.AddSubstitutionRule("AlwaysFailure", byID)
+
+	configs := substitutedConfigs(t, f)
+	want := map[string]*core.TestNodeConfig{"action_exact": exact, "action_other": wildcard, "by_id": byID}
+	if len(configs) != len(want) {
+		t.Fatalf("%d nodes substituted, want %d", len(configs), len(want))
+	}
+	for name, config := range want {
+		if configs[name] != config {
+			t.Errorf("[%v] substituted with the wrong rule", name)
+		}
+	}
+}
+
+func TestLoadSubstitutionRulesFromJSONOrder(t *testing.T) {
+	tests := []struct {
+		rules string
+		// return status of action_exact and action_other
+		exact, other core.NodeStatus
+	}{
+		// the first matching wildcard is applied, in the order of the document
+		{`"action_*": "Failing", "*": "Succeeding"`, core.NodeStatus_FAILURE, core.NodeStatus_FAILURE},
+		{`"*": "Succeeding", "action_*": "Failing"`, core.NodeStatus_SUCCESS, core.NodeStatus_SUCCESS},
+		{`"*": "Succeeding", "action_exact": "Failing"`, core.NodeStatus_FAILURE, core.NodeStatus_SUCCESS},
+	}
+	for _, tt := range tests {
+		f := newTestFactory()
+		err := f.LoadSubstitutionRulesFromJSON(`{
+			"TestNodeConfigs": {
+				"Failing": {"return_status": "FAILURE"},
+				"Succeeding": {"return_status": "SUCCESS"}
+			},
+			"SubstitutionRules": {` + tt.rules + `}
+		}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		configs := substitutedConfigs(t, f)
+		if exact, other := configs["action_exact"].ReturnStatus, configs["action_other"].ReturnStatus; exact != tt.exact || other != tt.other {
+			t.Errorf("%v: action_exact %v, action_other %v; want %v, %v", tt.rules, exact, other, tt.exact, tt.other)
+		}
+		// * matches the Sequence too
+		if len(configs) != 5 {
+			t.Errorf("%v: %d nodes substituted, want 5", tt.rules, len(configs))
+		}
+	}
+
+	for _, rules := range []string{`[]`, `{"action_*": 1}`, `{"action_*": "Failing"`} {
+		if err := newTestFactory().LoadSubstitutionRulesFromJSON(`{"SubstitutionRules": ` + rules + `}`); err == nil {
+			t.Errorf("no error for the rules %v", rules)
+		}
+	}
+}
@@ -1,40 +1,44 @@
-package actions
+package core
 
 import (
-	"github.com/gorustyt/go-behavior/core"
 	"sync/atomic"
 	"time"
 )
 
 type TestNode struct {
-	*core.StatefulActionNode
-	TestConfig *core.TestNodeConfig
+	*StatefulActionNode
+	TestConfig *TestNodeConfig
 	_completed atomic.Bool
-	_executor  core.ScriptFunction
+	_executor  ScriptFunction
 	timer      *time.Timer
 }
 
-func NewTestNode(name string, cfg *core.NodeConfig, args ...interface{}) *TestNode {
-	n := &TestNode{StatefulActionNode: core.NewStatefulActionNode(name, cfg)}
+func NewTestNode(name string, cfg *NodeConfig, args ...interface{}) *TestNode {
+	n := &TestNode{StatefulActionNode: NewStatefulActionNode(name, cfg)}
 	n.SetRegistrationID("TestNode")
 	n.StatefulActionNode.IStatefulActionNode = n
+	if len(args) > 0 {
+		n.setConfig(args[0].(*TestNodeConfig))
+	} else {
+		n.setConfig(NewTestNodeConfig())
+	}
 	return n
 }
-func (t *TestNode) setConfig(config *core.TestNodeConfig) {
-	if config.ReturnStatus == core.NodeStatus_IDLE {
+func (t *TestNode) setConfig(config *TestNodeConfig) {
+	if config.ReturnStatus == NodeStatus_IDLE {
 		panic("TestNode can not return IDLE")
 	}
 	t.TestConfig = config
 
 	if t.TestConfig.PostScript != "" {
-		executor, err := core.ParseScript(t.TestConfig.PostScript)
+		executor, err := ParseScript(t.TestConfig.PostScript)
 		if err != nil {
 			panic(err)
 		}
 		t._executor = executor
 	}
 }
-func (t *TestNode) OnStart() core.NodeStatus {
+func (t *TestNode) OnStart() NodeStatus {
 	if t.TestConfig.PreFunc != nil {
 		t.TestConfig.PreFunc()
 	}
@@ -50,20 +54,20 @@ func (t *TestNode) OnStart() core.NodeStatus {
 		t.EmitWakeUpSignal()
 	})
 
-	return core.NodeStatus_RUNNING
+	return NodeStatus_RUNNING
 }
 
-func (t *TestNode) OnRunning() core.NodeStatus {
+func (t *TestNode) OnRunning() NodeStatus {
 	if t._completed.Load() {
 		return t.OnCompleted()
 	}
-	return core.NodeStatus_RUNNING
+	return NodeStatus_RUNNING
 }
 
 func (t *TestNode) OnHalted() {
 	t.timer.Stop()
 }
-func (t *TestNode) OnCompleted() core.NodeStatus {
+func (t *TestNode) OnCompleted() NodeStatus {
 	if t._executor != nil {
 		t._executor(t.Config().Blackboard, t.Config().Enums)
 	}
@@ -1,6 +1,11 @@
 package core
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
 
 type Subtree struct {
 	TreeId       string
@@ -11,10 +16,12 @@ type Subtree struct {
 }
 
 type Tree struct {
-	uidCounter uint16
-	Subtrees   []*Subtree
-	manifests  map[string]*TreeNodeManifest
-	wakeUp     *WakeUpSignal
+	uidCounter   uint16
+	Subtrees     []*Subtree
+	manifests    map[string]*TreeNodeManifest
+	builtinNodes map[string]struct{}
+	wakeUp       *WakeUpSignal
+	tickCtx      *tickContext
 }
 
 func NewTree() *Tree {
@@ -35,61 +42,166 @@ func (t *Tree) Root() ITreeNode {
 	return subtreeNodes[0]
 }
 
+// BlackboardBackup returns a deep copy of the blackboards of the subtrees, in the order of Subtrees.
+// The entries remapped to a parent blackboard are stored only once, in the backup of the parent.
+func (t *Tree) BlackboardBackup() []*Blackboard {
+	res := make([]*Blackboard, len(t.Subtrees))
+	for i, subtree := range t.Subtrees {
+		res[i] = subtree.Blackboard.cloneLocal(nil)
+	}
+	return res
+}
+
+// BlackboardRestore copies back the values saved by BlackboardBackup.
+// The existing entries are updated in place and the entries created after the backup are removed.
+// The watchers are notified of every entry whose value changed; a removed entry is notified with a nil value.
+func (t *Tree) BlackboardRestore(backup []*Blackboard) error {
+	if len(backup) != len(t.Subtrees) {
+		return fmt.Errorf("BlackboardRestore: the backup has %v blackboards, but the tree has %v subtrees", len(backup), len(t.Subtrees))
+	}
+	changes := make([][]blackboardChange, len(t.Subtrees))
+	for i, subtree := range t.Subtrees {
+		changes[i] = backup[i].cloneInto(subtree.Blackboard)
+	}
+	// the subtrees may have cached entries of their parents that were removed
+	for _, subtree := range t.Subtrees {
+		subtree.Blackboard.dropRemappedEntries()
+	}
+	for i, subtree := range t.Subtrees {
+		for _, c := range changes[i] {
+			subtree.Blackboard.notifyChange(c.key, c.prev, c.value, nil)
+		}
+	}
+	return nil
+}
+
+// ApplyVisitor calls visitor on every node of the tree, subtrees included, in creation order
+func (t *Tree) ApplyVisitor(visitor func(node ITreeNode)) {
+	for _, subtree := range t.Subtrees {
+		for _, node := range subtree.Nodes {
+			visitor(node)
+		}
+	}
+}
+
 func (t *Tree) TickOnce() NodeStatus {
-	return t.tickRoot(ONCE_UNLESS_WOKEN_UP, 0)
+	status, _ := t.tickRoot(context.Background(), ONCE_UNLESS_WOKEN_UP, 0)
+	return status
 }
 
 func (t *Tree) TickExactlyOnce() NodeStatus {
-	return t.tickRoot(EXACTLY_ONCE, 0)
+	status, _ := t.tickRoot(context.Background(), EXACTLY_ONCE, 0)
+	return status
 }
 
 func (t *Tree) TickWhileRunning(sleepTimes ...time.Duration) NodeStatus {
+	status, _ := t.TickWhileRunningCtx(context.Background(), sleepTimes...)
+	return status
+}
+
+// TickOnceCtx is like TickOnce, but if ctx is canceled the tree is halted and ctx.Err() is returned.
+// The context is available to the nodes through Context until the next tick or HaltTree,
+// so that the nodes still RUNNING after the tick can observe it.
+func (t *Tree) TickOnceCtx(ctx context.Context) (NodeStatus, error) {
+	return t.tickRoot(ctx, ONCE_UNLESS_WOKEN_UP, 0)
+}
+
+// TickWhileRunningCtx is like TickWhileRunning, but if ctx is canceled the tree is halted and ctx.Err() is returned.
+// The context is available to the nodes through Context until the next tick or HaltTree.
+func (t *Tree) TickWhileRunningCtx(ctx context.Context, sleepTimes ...time.Duration) (NodeStatus, error) {
 	sleepTime := 10 * time.Millisecond
 	if len(sleepTimes) > 0 {
 		sleepTime = sleepTimes[0]
 	}
-	return t.tickRoot(WHILE_RUNNING, sleepTime)
+	return t.tickRoot(ctx, WHILE_RUNNING, sleepTime)
 }
 
 func (t *Tree) TickRoot(opt TickOption, sleepTime time.Duration) NodeStatus {
-	return t.tickRoot(opt, sleepTime)
+	status, _ := t.tickRoot(context.Background(), opt, sleepTime)
+	return status
 }
-func (t *Tree) tickRoot(opt TickOption, sleepTime time.Duration) NodeStatus {
+
+func (t *Tree) tickRoot(ctx context.Context, opt TickOption, sleepTime time.Duration) (NodeStatus, error) {
+	// the context is kept after returning, because the nodes still RUNNING
+	// (e.g. a ThreadedAction) use it until the tree is halted
+	t.tickCtx.set(ctx)
 	root := t.Root()
 	status := NodeStatus_IDLE
 	for status == NodeStatus_IDLE ||
 		(opt == WHILE_RUNNING && status == NodeStatus_RUNNING) {
+		if ctx.Err() != nil {
+			break
+		}
 		status = root.ExecuteTick()
+		// the previous tick might have triggered the wake-up:
+		// in this case, unless EXACTLY_ONCE, we tick again
 		for opt != EXACTLY_ONCE &&
 			status == NodeStatus_RUNNING &&
-			t.wakeUp.WaitFor(time.Duration(0)) {
+			ctx.Err() == nil &&
+			t.wakeUp.WaitFor(0) {
 			status = root.ExecuteTick()
 		}
 		if IsStatusCompleted(status) {
 			root.ResetStatus()
 		}
 		if status == NodeStatus_RUNNING && sleepTime > 0 {
-			time.Sleep(sleepTime)
+			t.wakeUp.WaitForCtx(ctx, sleepTime)
 		}
 	}
-
-	return status
+	if err := ctx.Err(); err != nil && !IsStatusCompleted(status) {
+		t.HaltTree()
+		return NodeStatus_IDLE, err
+	}
+	return status, nil
 }
 
 func (t *Tree) Init() {
 	t.wakeUp = NewWakeUpSignal()
+	t.tickCtx = &tickContext{}
 	for _, subtree := range t.Subtrees {
 		for _, node := range subtree.Nodes {
 			node.SetWakeUpInstance(t.wakeUp)
+			if setter, ok := node.(interface{ setTickContext(ctx *tickContext) }); ok {
+				setter.setTickContext(t.tickCtx)
+			}
 		}
 	}
 }
 
-func (t *Tree) haltTree() {
+// tickContext holds the context of the current tick, shared by the tree and its nodes
+type tickContext struct {
+	mutex sync.Mutex
+	ctx   context.Context
+}
+
+func (c *tickContext) get() context.Context {
+	if c == nil {
+		return context.Background()
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+func (c *tickContext) set(ctx context.Context) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ctx = ctx
+}
+
+// HaltTree halts all the nodes of the tree and releases the context of the last tick
+func (t *Tree) HaltTree() {
 	root := t.Root()
 	if root == nil {
 		return
 	}
+	defer t.tickCtx.set(nil)
 	// the halt should propagate to all the node if the nodes
 	// have been implemented correctly
 	t.Root().HaltNode()
@@ -1,16 +1,18 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 )
 
 type NodeType int
 
-func (p *NodeType) String() string {
-	switch *p {
+func (p NodeType) String() string {
+	switch p {
 	case NodeType_ACTION:
 		return "Action"
 	case NodeType_CONDITION:
@@ -27,27 +29,20 @@ func (p *NodeType) String() string {
 }
 
 func (p *NodeType) FromString(str string) error {
-	if str == "Action" {
+	switch str {
+	case "Action":
 		*p = NodeType_ACTION
-	}
-
-	if str == "Condition" {
+	case "Condition":
 		*p = NodeType_CONDITION
-	}
-
-	if str == "Control" {
+	case "Control":
 		*p = NodeType_CONTROL
-	}
-
-	if str == "Decorator" {
+	case "Decorator":
 		*p = NodeType_DECORATOR
-	}
-
-	if str == "SubTree" {
+	case "SubTree":
 		*p = NodeType_SUBTREE
+	default:
+		*p = NodeType_UNDEFINED
 	}
-
-	*p = NodeType_UNDEFINED
 	return nil
 }
 
@@ -62,8 +57,8 @@ const (
 
 type NodeStatus int
 
-func (n *NodeStatus) String() string {
-	switch *n {
+func (n NodeStatus) String() string {
+	switch n {
 	case NodeStatus_SUCCESS:
 		return "SUCCESS"
 	case NodeStatus_FAILURE:
@@ -79,34 +74,28 @@ func (n *NodeStatus) String() string {
 }
 
 func (n *NodeStatus) FromString(str string) error {
-	if str == "IDLE" {
+	switch str {
+	case "IDLE":
 		*n = NodeStatus_IDLE
-	}
-
-	if str == "RUNNING" {
+	case "RUNNING":
 		*n = NodeStatus_RUNNING
-	}
-
-	if str == "SUCCESS" {
+	case "SUCCESS":
 		*n = NodeStatus_SUCCESS
-	}
-
-	if str == "FAILURE" {
+	case "FAILURE":
 		*n = NodeStatus_FAILURE
-	}
-
-	if str == "SKIPPED" {
+	case "SKIPPED":
 		*n = NodeStatus_SKIPPED
+	default:
+		return fmt.Errorf("Cannot convert this to NodeStatus:%v ", str)
 	}
-
-	return fmt.Errorf("Cannot convert this to NodeStatus:%v ", str)
+	return nil
 }
 
-func (n *NodeStatus) StringColor(colored bool) string {
+func (n NodeStatus) StringColor(colored bool) string {
 	if !colored {
 		return n.String()
 	} else {
-		switch *n {
+		switch n {
 		case NodeStatus_SUCCESS:
 			return "\x1b[32mSUCCESS\x1b[0m" // RED
 		case NodeStatus_FAILURE:
@@ -231,7 +220,20 @@ type PortInfo struct {
 	description     string
 	defaultValue    any
 	defaultValueStr string
-	Name            string
+	// nil if the port accepts values of any type
+	typ  reflect.Type
+	Name string
+}
+
+// Type returns the type of the values of the port, nil if any type is allowed
+func (p *PortInfo) Type() reflect.Type {
+	return p.typ
+}
+
+// IsStronglyTyped tells if the port has a concrete type: a port without type, or whose type
+// is an interface (e.g. Input[any]), accepts values of any type.
+func (p *PortInfo) IsStronglyTyped() bool {
+	return p.typ != nil && p.typ.Kind() != reflect.Interface
 }
 
 func (p *PortInfo) Description() string {
@@ -248,11 +250,36 @@ func (p *PortInfo) DefaultValueString() string {
 	return p.defaultValueStr
 }
 
+// SetDefaultValue sets the default value; a port without type takes the type of the value
 func (p *PortInfo) SetDefaultValue(value any) {
+	p.defaultValueStr = valueToString(value)
+	p.defaultValue = value
+	if p.typ == nil && value != nil {
+		p.typ = reflect.TypeOf(value)
+	}
+}
+
+// valueToString writes the value of a port as it would be written in the XML
+func valueToString(value any) string {
+	if value == nil {
+		return ""
+	}
 	if v, ok := value.(fmt.Stringer); ok {
-		p.defaultValueStr = v.String()
+		return v.String()
 	}
-	p.defaultValue = value
+	// e.g. the types whose String has a pointer receiver
+	ptr := reflect.New(reflect.TypeOf(value))
+	ptr.Elem().Set(reflect.ValueOf(value))
+	if v, ok := ptr.Interface().(fmt.Stringer); ok {
+		return v.String()
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(value)
+	}
+	return ""
 }
 
 func (p *PortInfo) SetDescription(description string) {
@@ -298,16 +325,52 @@ func BidirectionalPortWithDefaultValue(name string, defaultValue any, desc ...st
 	return p
 }
 
+// typeOf returns the reflect.Type of T, also when T is an interface
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Input declares an input port of type T; Input[any] accepts values of any type
+func Input[T any](name string, desc ...string) *PortInfo {
+	p := InputPort(name, desc...)
+	p.typ = typeOf[T]()
+	return p
+}
+
+// InputWithDefault declares an input port of type T with a default value
+func InputWithDefault[T any](name string, defaultValue T, desc ...string) *PortInfo {
+	p := Input[T](name, desc...)
+	p.SetDefaultValue(defaultValue)
+	return p
+}
+
+// Output declares an output port of type T
+func Output[T any](name string, desc ...string) *PortInfo {
+	p := OutPort(name, desc...)
+	p.typ = typeOf[T]()
+	return p
+}
+
+// InOut declares a bidirectional port of type T
+func InOut[T any](name string, desc ...string) *PortInfo {
+	p := BidirectionalPort(name, desc...)
+	p.typ = typeOf[T]()
+	return p
+}
+
 type TreeNodeManifest struct {
 	Type           NodeType
 	RegistrationID string
 	Ports          map[string]*PortInfo
 	Metadata       []map[string]string
+	// DynamicPort, if set, returns the port for the attributes of the XML that aren't in Ports,
+	// or nil if the name isn't valid (e.g. the case_N ports of SwitchN)
+	DynamicPort func(name string) *PortInfo
 }
 
 func NewTreeNodeManifest(value any) *TreeNodeManifest {
 	v := &TreeNodeManifest{
-		Ports:    map[string]*PortInfo{},
+		Ports:    ProvidedPorts(value),
 		Metadata: make([]map[string]string, 0),
 		Type:     NodeType_UNDEFINED,
 	}
@@ -345,10 +408,14 @@ type TreeNode struct {
 	postConditionCallback  PostTickCallback
 	cond                   *sync.Cond
 	wake_up                *WakeUpSignal
+	tickCtx                *tickContext
 	registrationID         string
 	pre_parsed             []ScriptFunction
 	post_parsed            []ScriptFunction
 	state_change_signal    *Signal
+	// self is the concrete node that embeds this TreeNode. Go has no virtual methods:
+	// ExecuteTick and HaltNode call Tick and Halt through it, to reach the implementation of the node.
+	self ITreeNode
 }
 
 func NewTreeNode(name string, cfg *NodeConfig) *TreeNode {
@@ -367,18 +434,28 @@ func NewTreeNode(name string, cfg *NodeConfig) *TreeNode {
 
 func (n *TreeNode) SetStatus(status NodeStatus) {
 	if status == NodeStatus_IDLE {
-		panic(fmt.Sprintf("Node [", "", "]: you are not allowed to set manually the status to IDLE. If you know what you are doing (?) use resetStatus() instead."))
+		panic(fmt.Sprintf("Node [%v]: you are not allowed to set manually the status to IDLE. If you know what you are doing (?) use resetStatus() instead.", n.name))
 	}
 	var prev_status NodeStatus
 	n.mutex.Lock()
 	prev_status = n.status
+	n.status = status
 	n.mutex.Unlock()
 	if prev_status != status {
 		n.cond.Broadcast()
-		n.state_change_signal.Notify(prev_status, status)
+		n.state_change_signal.Notify(time.Now(), n, prev_status, status)
 	}
 }
 
+// SubscribeToStatusChange registers a callback invoked every time the status of the node changes.
+// The callback is executed synchronously, by the goroutine that changed the status.
+// Use the returned Subscriber to stop receiving the notifications.
+func (n *TreeNode) SubscribeToStatusChange(callback StatusChangeCallback) *Subscriber {
+	return n.state_change_signal.Subscribe(func(args ...any) {
+		callback(args[0].(time.Time), args[1].(*TreeNode), args[2].(NodeStatus), args[3].(NodeStatus))
+	})
+}
+
 func (n *TreeNode) EmitWakeUpSignal() {
 	if n.wake_up != nil {
 		n.wake_up.EmitSignal()
@@ -390,9 +467,27 @@ func (n *TreeNode) RequiresWakeUp() bool {
 }
 func (n *TreeNode) Tick() NodeStatus {
 	panic("not ok")
-	return NodeStatus_RUNNING
 }
+
+// setSelf is called by the factory with the node that embeds n
+func (n *TreeNode) setSelf(node ITreeNode) {
+	n.self = node
+}
+
+// tick calls the Tick of the concrete node
+func (n *TreeNode) tick() NodeStatus {
+	if n.self != nil {
+		return n.tickWithTaggedPorts(n.self)
+	}
+	return n.Tick()
+}
+
 func (n *TreeNode) ExecuteTick() NodeStatus {
+	return n.executeTick(n.tick)
+}
+
+// executeTick wraps tick with the pre and post conditions and the injected callbacks
+func (n *TreeNode) executeTick(tick func() NodeStatus) NodeStatus {
 	new_status := n.status
 
 	// a pre-condition may return the new status.
@@ -419,7 +514,7 @@ func (n *TreeNode) ExecuteTick() NodeStatus {
 
 		// Call the ACTUAL tick
 		if !substituted {
-			new_status = n.Tick()
+			new_status = tick()
 		}
 	}
 
@@ -443,6 +538,9 @@ func (n *TreeNode) ExecuteTick() NodeStatus {
 	// preserve the IDLE state if skipped, but communicate SKIPPED to parent
 	if new_status != NodeStatus_SKIPPED {
 		n.SetStatus(new_status)
+	} else {
+		// the subscribers are still informed that the node was skipped
+		n.state_change_signal.Notify(time.Now(), n, n.Status(), NodeStatus_SKIPPED)
 	}
 	return new_status
 }
@@ -458,13 +556,28 @@ func (n *TreeNode) SetWakeUpInstance(instance *WakeUpSignal) {
 	n.wake_up = instance
 }
 
+func (n *TreeNode) setTickContext(ctx *tickContext) {
+	n.tickCtx = ctx
+}
+
+// Context returns the context passed to the last Tree.TickOnceCtx or Tree.TickWhileRunningCtx,
+// until the tree is halted, or context.Background if the tree is not ticked with a context.
+// Long-running actions can use it to observe the cancellation.
+func (n *TreeNode) Context() context.Context {
+	return n.tickCtx.get()
+}
+
 // / The method used to interrupt the execution of a RUNNING node.
 // / Only Async nodes that may return RUNNING should implement it.
 func (n *TreeNode) Halt() {
 
 }
 func (n *TreeNode) HaltNode() {
-	n.Halt()
+	if halter, ok := n.self.(interface{ Halt() }); ok {
+		halter.Halt()
+	} else {
+		n.Halt()
+	}
 	ex := n.post_parsed[PostCond_ON_HALTED]
 	if ex != nil {
 		ex(n.Config().Blackboard, n.Config().Enums)
@@ -543,10 +656,11 @@ func (n *TreeNode) ResetStatus() {
 	var prev_status NodeStatus
 	n.mutex.Lock()
 	prev_status = n.status
+	n.status = NodeStatus_IDLE
 	n.mutex.Unlock()
 	if prev_status != NodeStatus_IDLE {
 		n.cond.Broadcast()
-		n.state_change_signal.Notify(prev_status, NodeStatus_IDLE)
+		n.state_change_signal.Notify(time.Now(), n, prev_status, NodeStatus_IDLE)
 	}
 }
 
@@ -577,7 +691,8 @@ func (n *TreeNode) FullPath() string {
 	return n.config.Path
 }
 
-func (n *TreeNode) registrationName() string {
+// RegistrationName is the ID used to register the node in the factory
+func (n *TreeNode) RegistrationName() string {
 	return n.registrationID
 }
 
@@ -585,24 +700,35 @@ func (n *TreeNode) Config() *NodeConfig {
 	return n.config
 }
 
+// SetOutput is SetOutput for the values of any type; it panics on error
 func (n *TreeNode) SetOutput(key string, value any) {
+	if err := n.setOutput(key, value); err != nil {
+		panic(err)
+	}
+}
+
+func (n *TreeNode) setOutput(key string, value any) error {
 	remappedKey, ok := n.config.OutputPorts[key]
 	if !ok {
-		panic(fmt.Sprintf("setOutput() failed:  NodeConfig::output_ports does not contain the key: [%v]", key))
+		return &PortError{Node: n.FullPath(), Port: key, Err: ErrMissingPort}
 	}
 	if remappedKey == "=" {
-		n.config.Blackboard.Set(key, value)
-		return
-	}
-	ss, ok := IsBlackboardPointer(remappedKey)
-	if !ok {
-		panic("setOutput requires a blackboard pointer. Use {}")
+		remappedKey = key
+	} else if stripped, ok := IsBlackboardPointer(remappedKey); ok {
+		remappedKey = stripped
+	} else {
+		return &PortError{Node: n.FullPath(), Port: key, Err: fmt.Errorf("an output port requires a blackboard pointer, use {}: [%v]", remappedKey)}
 	}
-
 	if value == nil {
-		panic("setOutput nil is not allowed")
+		return &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: errors.New("nil is not allowed")}
+	}
+	if n.config.Blackboard == nil {
+		return &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: fmt.Errorf("%w: invalid blackboard", ErrMissingKey)}
 	}
-	n.config.Blackboard.Set(ss, value)
+	if err := n.config.Blackboard.set(remappedKey, value, nil, n); err != nil {
+		return &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: err}
+	}
+	return nil
 }
 
 func (n *TreeNode) GetRawPortValue(key string) string {
@@ -616,6 +742,161 @@ func (n *TreeNode) GetRawPortValue(key string) string {
 	return remap
 }
 
+// GetInputString returns the string assigned to the port in the XML or, if the port
+// is remapped, the value stored in the blackboard converted to string.
+// Nodes read their ports with GetInput[string]; GetInputString is an escape hatch for the
+// code that needs the raw text of the port, ignoring the default value of the manifest.
+func (n *TreeNode) GetInputString(key string) (string, error) {
+	portValueStr, ok := n.config.InputPorts[key]
+	if !ok {
+		return "", &PortError{Node: n.FullPath(), Port: key, Err: ErrMissingPort}
+	}
+	remappedKey, err := GetRemappedKey(key, portValueStr)
+	if err != nil {
+		// pure string, not a blackboard key
+		return portValueStr, nil
+	}
+	if n.config.Blackboard == nil {
+		return "", &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: fmt.Errorf("%w: invalid blackboard", ErrMissingKey)}
+	}
+	entry := n.config.Blackboard.GetEntry(remappedKey)
+	if entry == nil {
+		return "", &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: ErrMissingKey}
+	}
+	entry.entryMutex.Lock()
+	defer entry.entryMutex.Unlock()
+	if str, ok := entry.Value.(string); ok {
+		return str, nil
+	}
+	// numbers, booleans and Stringers are converted
+	str := valueToString(entry.Value)
+	if str == "" {
+		return "", &PortError{Node: n.FullPath(), Port: key, Key: remappedKey,
+			Err: fmt.Errorf("%w: the entry can't be converted to string", ErrTypeMismatch)}
+	}
+	return str, nil
+}
+
+// getInputValue returns the value of the input port converted to typ: the literal value
+// assigned in the XML, the default of the manifest or the value of the remapped entry.
+// The errors are *PortError.
+func (n *TreeNode) getInputValue(key string, typ reflect.Type) (any, error) {
+	portValueStr, ok := n.config.InputPorts[key]
+	if !ok {
+		return nil, &PortError{Node: n.FullPath(), Port: key, Err: ErrMissingPort}
+	}
+	// special case. Empty port value, we should use the default value,
+	// if available in the model. BUT, if the default is a string,
+	// then an empty string might be a valid value
+	if portValueStr == "" && n.config.Manifest != nil {
+		if port, ok := n.config.Manifest.Ports[key]; ok && port.DefaultValue() != nil {
+			if _, isString := port.DefaultValue().(string); !isString {
+				return n.convertInput(key, "", port.DefaultValue(), typ)
+			}
+		}
+	}
+	remappedKey, err := GetRemappedKey(key, portValueStr)
+	if err != nil {
+		// pure string, not a blackboard key
+		return n.convertInput(key, "", portValueStr, typ)
+	}
+	if n.config.Blackboard == nil {
+		return nil, &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: fmt.Errorf("%w: invalid blackboard", ErrMissingKey)}
+	}
+	entry := n.config.Blackboard.GetEntry(remappedKey)
+	if entry == nil {
+		return nil, &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: ErrMissingKey}
+	}
+	entry.entryMutex.Lock()
+	value := entry.Value
+	entry.entryMutex.Unlock()
+	if value == nil {
+		return nil, &PortError{Node: n.FullPath(), Port: key, Key: remappedKey, Err: ErrUninitializedEntry}
+	}
+	return n.convertInput(key, remappedKey, value, typ)
+}
+
+// convertInput converts the value of a port to typ; the strings that are names of scripting enums
+// are converted to their values when typ is an integer, the numbers, booleans and Stringers are
+// converted to string when typ is string.
+func (n *TreeNode) convertInput(port, remappedKey string, value any, typ reflect.Type) (any, error) {
+	if _, ok := value.(string); !ok && typ == reflect.TypeOf("") {
+		if str := valueToString(value); str != "" {
+			return str, nil
+		}
+	}
+	if str, ok := value.(string); ok && typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64 {
+		if v, ok := n.config.Enums[str]; ok {
+			return reflect.ValueOf(v).Convert(typ).Interface(), nil
+		}
+	}
+	res, err := convertValue(value, typ)
+	if err != nil {
+		return nil, &PortError{Node: n.FullPath(), Port: port, Key: remappedKey, Err: err}
+	}
+	return res, nil
+}
+
+// PortError is the error returned by GetInput and SetOutput
+type PortError struct {
+	// full path of the node
+	Node string
+	Port string
+	// the key of the blackboard the port is remapped to, empty for literal values
+	Key string
+	Err error
+}
+
+func (e *PortError) Error() string {
+	if e.Key != "" {
+		return fmt.Sprintf("port [%v] of node [%v], remapped to [%v]: %v", e.Port, e.Node, e.Key, e.Err)
+	}
+	return fmt.Sprintf("port [%v] of node [%v]: %v", e.Port, e.Node, e.Err)
+}
+
+func (e *PortError) Unwrap() error {
+	return e.Err
+}
+
+// treeNode gives access to the TreeNode embedded by the concrete nodes
+func (n *TreeNode) treeNode() *TreeNode {
+	return n
+}
+
+func asTreeNode(node ITreeNode) *TreeNode {
+	if n, ok := node.(interface{ treeNode() *TreeNode }); ok {
+		return n.treeNode()
+	}
+	panic(fmt.Sprintf("the node [%T] doesn't embed a TreeNode", node))
+}
+
+// GetInput reads the input port of the node: the literal value in the XML, the entry of the
+// blackboard for "{key}" and "=", or the default value of the manifest. The strings are converted
+// to T with ConvFromString, the numbers are widened. The errors are *PortError, wrapping
+// ErrMissingPort, ErrMissingKey, ErrUninitializedEntry or ErrTypeMismatch.
+func GetInput[T any](node ITreeNode, key string) (res T, err error) {
+	value, err := asTreeNode(node).getInputValue(key, typeOf[T]())
+	if err != nil {
+		return res, err
+	}
+	return value.(T), nil
+}
+
+// GetInputOr returns the value of the input port, or defaultValue if it can't be read
+func GetInputOr[T any](node ITreeNode, key string, defaultValue T) T {
+	res, err := GetInput[T](node, key)
+	if err != nil {
+		return defaultValue
+	}
+	return res
+}
+
+// SetOutput writes the output port of the node into the entry of the blackboard it's remapped to.
+// The errors are *PortError.
+func SetOutput[T any](node ITreeNode, key string, value T) error {
+	return asTreeNode(node).setOutput(key, value)
+}
+
 func IsBlackboardPointer(str string) (res string, ok bool) {
 	if len(str) < 3 {
 		return str, false
@@ -632,7 +913,7 @@ func IsBlackboardPointer(str string) (res string, ok bool) {
 	size := (last_index - front_index) + 1
 	valid := size >= 3 && str[front_index] == '{' && str[last_index] == '}'
 	if valid {
-		res = str[front_index+1:]
+		res = str[front_index+1 : last_index]
 	}
 	return res, valid
 }
@@ -672,62 +953,3 @@ func GetRemappedKey(portName string, remappedPort string) (res string, err error
 	}
 	return res, errors.New("not a blackboard pointer")
 }
-func (n *TreeNode) GetInput(key string, destination any) (res any, err error) {
-	ParseString := func(str string) any {
-		switch destination.(type) {
-		case *NodeType, *PortDirection:
-			it, ok := n.config.Enums[str]
-			if ok {
-				reflect.ValueOf(destination).Elem().Set(reflect.ValueOf(it))
-			}
-		}
-		return ConvFromString(str, destination)
-	}
-	portValueStr, ok := n.config.InputPorts[key]
-	if !ok {
-		return res, fmt.Errorf("getInput() of node `%v` failed because NodeConfig::input_ports does not contain the key: [%v]", n.FullPath(), key)
-	}
-
-	// special case. Empty port value, we should use the default value,
-	// if available in the model.
-	// BUT, it the port type is a string, then an empty string might be
-	// a valid value
-	if portValueStr == "" && n.config.Manifest != nil {
-		portManifest := n.config.Manifest.Ports[key]
-		defaultValue := portManifest.DefaultValue()
-		_, ok := defaultValue.(string)
-		if defaultValue != nil && !ok {
-			destination = defaultValue
-			return destination, nil
-		}
-	}
-
-	remappedRes, err := GetRemappedKey(key, portValueStr)
-	if err != nil {
-		return res, err
-	}
-	// pure string, not a blackboard key
-	if remappedRes != "" {
-		destination = ParseString(portValueStr)
-		return res, nil
-	}
-	remappedKey := remappedRes
-
-	if n.config.Blackboard == nil {
-		return res, fmt.Errorf("getInput(): trying to access an invalid Blackboard")
-	}
-
-	if anyRef := n.config.Blackboard.GetAnyLocked(remappedKey); anyRef != nil {
-		val := anyRef()
-		v, ok := val.Value.(string)
-		if ok {
-			destination = ParseString(v)
-		} else {
-			return destination, nil
-		}
-	}
-
-	return res, fmt.Errorf("getInput() failed because it was unable to find the key [%v] remapped to [%v]", key,
-		remappedKey)
-
-}
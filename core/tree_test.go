@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// newBackupTestTree returns a tree whose second subtree remaps [x] to [a] of the first one
+func newBackupTestTree() *Tree {
+	parent := NewBlackboard(nil)
+	child := NewBlackboard(parent)
+	child.AddSubtreeRemapping("x", "a")
+	tree := NewTree()
+	tree.Subtrees = []*Subtree{{TreeId: "Main", Blackboard: parent}, {TreeId: "Sub", Blackboard: child}}
+	return tree
+}
+
+func TestBlackboardClone(t *testing.T) {
+	tree := newBackupTestTree()
+	parent, child := tree.Subtrees[0].Blackboard, tree.Subtrees[1].Blackboard
+	parent.Set("a", []int{1, 2})
+	child.Set("local", map[string]int{"k": 1})
+
+	clone := child.Clone()
+	clone.Set("x", []int{3})
+	BBGetOr[map[string]int](clone, "local", nil)["k"] = 2
+	BBGetOr[[]int](clone, "x", nil)[0] = 4
+
+	if got := BBGetOr[[]int](parent, "a", nil); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("the original parent entry is %v after changing the clone", got)
+	}
+	if got := BBGetOr[map[string]int](child, "local", nil); got["k"] != 1 {
+		t.Fatalf("the original entry is %v after changing the clone", got)
+	}
+	// the clone has its own parent, with the same remapping
+	if got := BBGetOr[[]int](clone.parentBb, "a", nil); !reflect.DeepEqual(got, []int{4}) {
+		t.Fatalf("the parent of the clone has [a] = %v, want [4]", got)
+	}
+}
+
+func TestBlackboardRestore(t *testing.T) {
+	tests := []struct {
+		name string
+		// change is applied after the backup
+		change func(parent, child *Blackboard)
+		// wantParent and wantChild are the keys and values after the restore
+		wantParent, wantChild map[string]any
+		// notified are the keys notified to the watcher of the parent, with the new values
+		notified map[string]any
+	}{
+		{
+			name:       "values restored",
+			change:     func(parent, child *Blackboard) { parent.Set("a", 2); child.Set("local", "changed") },
+			wantParent: map[string]any{"a": 1},
+			wantChild:  map[string]any{"x": 1, "local": "v"},
+			notified:   map[string]any{"a": 1},
+		},
+		{
+			name:       "remapped write restored",
+			change:     func(parent, child *Blackboard) { child.Set("x", 3) },
+			wantParent: map[string]any{"a": 1},
+			wantChild:  map[string]any{"x": 1, "local": "v"},
+			notified:   map[string]any{"a": 1},
+		},
+		{
+			name: "entries created after the backup removed",
+			change: func(parent, child *Blackboard) {
+				parent.Set("new", 1)
+				child.Set("new_local", 1)
+			},
+			wantParent: map[string]any{"a": 1},
+			wantChild:  map[string]any{"x": 1, "local": "v"},
+			notified:   map[string]any{"new": nil},
+		},
+		{
+			name:       "entries removed after the backup created again",
+			change:     func(parent, child *Blackboard) { parent.Unset("a"); child.Unset("local") },
+			wantParent: map[string]any{"a": 1},
+			wantChild:  map[string]any{"x": 1, "local": "v"},
+			notified:   map[string]any{"a": 1},
+		},
+		{
+			name:       "unchanged entries not notified",
+			change:     func(parent, child *Blackboard) {},
+			wantParent: map[string]any{"a": 1},
+			wantChild:  map[string]any{"x": 1, "local": "v"},
+			notified:   map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newBackupTestTree()
+			parent, child := tree.Subtrees[0].Blackboard, tree.Subtrees[1].Blackboard
+			parent.Set("a", 1)
+			child.Set("local", "v")
+			child.GetEntry("x")
+			backup := tree.BlackboardBackup()
+
+			tt.change(parent, child)
+			notified := map[string]any{}
+			parent.WatchPrefix("", func(key string, prev, value any, writer *TreeNode) {
+				notified[key] = value
+			})
+			if err := tree.BlackboardRestore(backup); err != nil {
+				t.Fatal(err)
+			}
+
+			for bb, want := range map[*Blackboard]map[string]any{parent: tt.wantParent, child: tt.wantChild} {
+				for key, value := range want {
+					if entry := bb.GetEntry(key); entry == nil || entry.Value != value {
+						t.Errorf("[%v] = %v, want %v", key, entry, value)
+					}
+				}
+				for _, key := range bb.GetKeys() {
+					if _, ok := want[key]; !ok {
+						t.Errorf("[%v] wasn't removed", key)
+					}
+				}
+			}
+			if !reflect.DeepEqual(notified, tt.notified) {
+				t.Errorf("notified %v, want %v", notified, tt.notified)
+			}
+		})
+	}
+
+	if err := newBackupTestTree().BlackboardRestore(nil); err == nil {
+		t.Fatal("BlackboardRestore accepted a backup of another tree")
+	}
+}
+
+func TestTickContextUntilHalt(t *testing.T) {
+	type ctxKey struct{}
+	release, tickCtx := make(chan struct{}), make(chan context.Context, 1)
+	f := NewBehaviorTreeFactory()
+	err := Register(f, "Threaded", func(name string, cfg *NodeConfig) *threadedTestNode {
+		return &threadedTestNode{ThreadedAction: NewThreadedAction(name, cfg), tick: func(n *threadedTestNode) NodeStatus {
+			<-release
+			// the context of the tree, read after TickOnceCtx returns
+			tickCtx <- n.ActionNodeBase.Context()
+			return NodeStatus_SUCCESS
+		}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := f.CreateTreeFromText(`<root BTCPP_format="4"><BehaviorTree ID="Main"><Threaded/></BehaviorTree></root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "tick"))
+	defer cancel()
+	if status, err := tree.TickOnceCtx(ctx); status != NodeStatus_RUNNING || err != nil {
+		t.Fatalf("TickOnceCtx: %v, %v", status, err)
+	}
+	close(release)
+	if v := (<-tickCtx).Value(ctxKey{}); v != "tick" {
+		t.Fatalf("context value after TickOnceCtx %v, want tick", v)
+	}
+	if status := waitStatus(t, tree.Root()); status != NodeStatus_SUCCESS {
+		t.Fatalf("status %v, want SUCCESS", status)
+	}
+
+	tree.HaltTree()
+	if v := tree.Root().(*threadedTestNode).ActionNodeBase.Context().Value(ctxKey{}); v != nil {
+		t.Fatalf("context value after HaltTree %v, want none", v)
+	}
+}
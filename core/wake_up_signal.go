@@ -1,46 +1,50 @@
 package core
 
 import (
-	"sync"
-	"sync/atomic"
+	"context"
 	"time"
 )
 
+// WakeUpSignal is used by the asynchronous nodes to wake up the goroutine ticking the tree.
+// A signal emitted while nobody is waiting is kept until the next wait.
 type WakeUpSignal struct {
-	mutex_ *sync.Mutex
-	cv_    *sync.Cond
-	ready_ atomic.Bool
+	ch chan struct{}
 }
 
 func NewWakeUpSignal() *WakeUpSignal {
-	mu := &sync.Mutex{}
-	return &WakeUpSignal{
-		mutex_: mu,
-		cv_:    sync.NewCond(mu),
-	}
+	return &WakeUpSignal{ch: make(chan struct{}, 1)}
 }
 
-// / Return true if the timeout was NOT reached and the
-// / signal was received.
+// WaitFor returns true if the signal was received before the timeout was reached.
+func (s *WakeUpSignal) WaitFor(timeout time.Duration) bool {
+	return s.WaitForCtx(context.Background(), timeout)
+}
 
-func (s *WakeUpSignal) WaitFor(usec time.Duration) bool {
-	timer := time.NewTimer(usec)
+// WaitForCtx is like WaitFor, but it also returns false as soon as ctx is done.
+func (s *WakeUpSignal) WaitForCtx(ctx context.Context, timeout time.Duration) bool {
+	select {
+	case <-s.ch:
+		return true
+	default:
+	}
+	if timeout <= 0 {
+		return false
+	}
+	timer := time.NewTimer(timeout)
 	defer timer.Stop()
-	defer s.ready_.Store(false)
-	for {
-		select {
-		case <-timer.C:
-			return false
-		default:
-			if s.ready_.Load() {
-				s.cv_.Wait()
-				return true
-			}
-		}
+	select {
+	case <-s.ch:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
 	}
 }
 
 func (s *WakeUpSignal) EmitSignal() {
-	s.ready_.Store(true)
-	s.cv_.Broadcast()
+	select {
+	case s.ch <- struct{}{}:
+	default:
+	}
 }
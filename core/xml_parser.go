@@ -6,8 +6,8 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"github.com/gorustyt/go-behavior/decorators"
 	"io"
+	"maps"
 	"os"
 	"reflect"
 	"strings"
@@ -17,6 +17,25 @@ var (
 	ErrorsInvalidTag = errors.New("invalid tag")
 )
 
+// checkPortType tells if a port can be connected to an existing entry: the ports and the entries
+// without a type accept any type, and the strings can be converted to and from the other types.
+func checkPortType(entry *Entry, port *PortInfo) error {
+	entry.entryMutex.Lock()
+	entryType, createdBy := entry.Type(), entry.createdBy
+	entry.entryMutex.Unlock()
+	if entryType == nil || !port.IsStronglyTyped() || entryType == port.Type() {
+		return nil
+	}
+	if entryType.Kind() == reflect.String || port.Type().Kind() == reflect.String {
+		return nil
+	}
+	if createdBy == "" {
+		createdBy = "the blackboard"
+	}
+	return fmt.Errorf("%w: the entry was created with type [%v] by [%v], the port has type [%v]",
+		ErrTypeMismatch, entryType, createdBy, port.Type())
+}
+
 type SubtreeModel struct {
 	ports map[string]*PortInfo
 }
@@ -31,10 +50,12 @@ type xmlParser struct {
 	suffixCount   int
 }
 
-func NewXmlParser() Parser {
+func NewXmlParser(factory *BehaviorTreeFactory) Parser {
 	return &xmlParser{
-		stack:     &stack{},
-		treesRoot: NewSortMap[string, *XmlTag](),
+		stack:         &stack{},
+		treesRoot:     NewSortMap[string, *XmlTag](),
+		factory:       factory,
+		subtreeModels: map[string]*SubtreeModel{},
 	}
 }
 
@@ -120,9 +141,15 @@ func (p *xmlParser) createNodeFromXML(element *XmlTag,
 
 	var config NodeConfig
 	config.Blackboard = blackboard
+	config.InputPorts = map[string]string{}
+	config.OutputPorts = map[string]string{}
+	config.PreConditions = map[PreCond]string{}
+	config.PostConditions = map[PostCond]string{}
 	config.Path = prefixPath + instanceName
 	config.Uid = outputTree.GetUID()
-	config.Manifest = b.TreeNodeManifest
+	if ok {
+		config.Manifest = b.TreeNodeManifest
+	}
 
 	if typeId == instanceName {
 		config.Path += fmt.Sprintf("::%v", config.Uid)
@@ -146,6 +173,10 @@ func (p *xmlParser) createNodeFromXML(element *XmlTag,
 
 	if nodeType == NodeType_SUBTREE {
 		config.InputPorts = portRemap
+		// keep _autoremap among the ports of the SubTree node, so that it is written back to XML
+		if autoremap := element.GetAttr("_autoremap"); autoremap != "" {
+			config.InputPorts["_autoremap"] = autoremap
+		}
 		newNode, err = p.factory.InstantiateTreeNode(instanceName,
 			NodeType_SUBTREE.String(),
 			&config)
@@ -153,8 +184,7 @@ func (p *xmlParser) createNodeFromXML(element *XmlTag,
 			return nil, err
 		}
 
-		subtreeNode, ok := newNode.(*decorators.SubTreeNode)
-		if ok {
+		if subtreeNode, ok := newNode.(interface{ SetSubtreeID(ID string) }); ok {
 			subtreeNode.SetSubtreeID(typeId)
 		}
 	} else {
@@ -162,9 +192,17 @@ func (p *xmlParser) createNodeFromXML(element *XmlTag,
 			return tree, fmt.Errorf("missing manifest for element_ID: %v . It shouldn't happen. Please report this issue", elementId)
 		}
 		manifest := b.TreeNodeManifest
+		// the ports of the manifest and the dynamic ports used by this element
+		ports := maps.Clone(manifest.Ports)
 		//Check that name in remapping can be found in the manifest
 		for nameInSubtree := range portRemap {
-			_, ok = manifest.Ports[nameInSubtree]
+			_, ok = ports[nameInSubtree]
+			if !ok && manifest.DynamicPort != nil {
+				if port := manifest.DynamicPort(nameInSubtree); port != nil {
+					ports[nameInSubtree] = port
+					ok = true
+				}
+			}
 			if !ok {
 				return tree, fmt.Errorf("possible typo? In the XML, you tried to remap port \"%v\" in node [%v(type :%v)], but the manifest of this node does not contain a port with this name",
 					nameInSubtree, config.Path, typeId)
@@ -172,40 +210,35 @@ func (p *xmlParser) createNodeFromXML(element *XmlTag,
 		}
 
 		// Initialize the ports in the BB to set the type
-		for portName, portInfo := range manifest.Ports {
+		for portName, portInfo := range ports {
 			remappedPort, ok := portRemap[portName]
 			if !ok {
 				continue
 			}
+			// only the ports remapped to the blackboard create an entry
 			portKey, err := GetRemappedKey(portName, remappedPort)
-			if err != nil {
-				return nil, err
-			}
-			if portKey != "" {
+			if err == nil && portKey != "" {
 				// port_key will contain the key to find the entry in the blackboard
 				// if the entry already exists, check that the type is the same
 				prevInfo := blackboard.GetEntry(portKey)
 				if prevInfo != nil {
 					// Check consistency of types.
-					portTypeMismatch := reflect.TypeOf(prevInfo.Value) != reflect.TypeOf(portInfo.defaultValue)
-
-					// special case related to convertFromString
-					_, stringInput := prevInfo.Value.(string)
-					if portTypeMismatch && !stringInput {
+					if err := checkPortType(prevInfo, portInfo); err != nil {
 						blackboard.DebugMessage()
-
-						return tree, fmt.Errorf("The creation of the tree failed because the port [%v] was initially created with type [] and, later type [] was used somewhere else.", portKey)
+						return tree, fmt.Errorf("The creation of the tree failed because of the port [%v] of node [%v], remapped to [%v]: %w", portName, config.Path, portKey, err)
 					}
 				} else {
 					// not found, insert for the first time.
-					blackboard.CreateEntry(portKey, portInfo)
+					if entry := blackboard.CreateEntry(portKey, portInfo); entry != nil {
+						entry.createdBy = config.Path
+					}
 				}
 			}
 		}
 
 		// Set the port direction in config
 		for portName, v := range portRemap {
-			portIt, ok := manifest.Ports[portName]
+			portIt, ok := ports[portName]
 			if ok {
 				direction := portIt.direction
 				if direction != PortDirection_OUTPUT {
@@ -218,7 +251,7 @@ func (p *xmlParser) createNodeFromXML(element *XmlTag,
 		}
 
 		// use default value if available for empty ports. Only inputs
-		for portName, portInfo := range manifest.Ports {
+		for portName, portInfo := range ports {
 			direction := portInfo.direction
 			_, ok := config.InputPorts[portName]
 			if direction != PortDirection_OUTPUT && !ok && portInfo.defaultValue != nil {
@@ -235,9 +268,10 @@ func (p *xmlParser) createNodeFromXML(element *XmlTag,
 	// add the pointer of this node to the parent
 	if nodeParent != nil {
 
-		if controlParent, ok := nodeParent.(*ControlNode); ok {
+		// the concrete nodes embed ControlNode or DecoratorNode, so check the methods instead of the type
+		if controlParent, ok := nodeParent.(interface{ AddChild(child ITreeNode) }); ok {
 			controlParent.AddChild(newNode)
-		} else if decoratorParent, ok := nodeParent.(*DecoratorNode); ok {
+		} else if decoratorParent, ok := nodeParent.(interface{ SetChild(child ITreeNode) error }); ok {
 			err = decoratorParent.SetChild(newNode)
 			if err != nil {
 				return nil, err
@@ -268,6 +302,12 @@ func (p *xmlParser) recursivelyCreateSubtree(parentNode ITreeNode,
 				return err
 			}
 		}
+		// the nodes requiring a given number of children check it once they are added
+		if validator, ok := node.(interface{ ValidateChildren() error }); ok {
+			if err = validator.ValidateChildren(); err != nil {
+				return fmt.Errorf("node [%v]: %v", node.FullPath(), err)
+			}
+		}
 	} else { // special case: SubTreeNode
 		newBb := NewBlackboard(blackboard)
 		subtreeId := element.GetAttr("ID")
@@ -350,10 +390,14 @@ func (p *xmlParser) createSubtree(
 	prefixPath string,
 	outputTree *Tree,
 	blackboard *Blackboard, rootNode ITreeNode) (err error) {
-	rootElement, ok := p.treesRoot.Get(treeId)
+	treeElement, ok := p.treesRoot.Get(treeId)
 	if !ok {
 		return fmt.Errorf("can't find a tree with name: %v", treeId)
 	}
+	if len(treeElement.Children) == 0 {
+		return fmt.Errorf("the tree [%v] is empty", treeId)
+	}
+	rootElement := treeElement.Children[0]
 
 	// Append a new subtree to the list
 	newTree := &Subtree{}
@@ -367,14 +411,15 @@ func (p *xmlParser) createSubtree(
 func (p *xmlParser) InstantiateTree(rootBlackboard *Blackboard, mainTreeId string) (tree *Tree, err error) {
 	tree = NewTree()
 	if mainTreeId == "" {
-		root := p.rootTag[0]
-		mainTreeId = root.GetAttr("main_tree_to_execute")
-		if mainTreeId == "" && len(p.rootTag) == 1 {
+		if len(p.rootTag) > 0 {
+			mainTreeId = p.rootTag[0].GetAttr("main_tree_to_execute")
+		}
+		if mainTreeId == "" {
+			if p.treesRoot.Len() != 1 {
+				return tree, fmt.Errorf("[main_tree_to_execute] was not specified correctly")
+			}
 			// special case: there is only one registered BT.
-			v, _ := p.treesRoot.Front()
-			mainTreeId = v.TagName()
-		} else {
-			return tree, fmt.Errorf("[main_tree_to_execute] was not specified correctly")
+			mainTreeId = p.RegisteredBehaviorTrees()[0]
 		}
 	}
 	if rootBlackboard == nil {
@@ -403,18 +448,21 @@ func (p *xmlParser) parseSubtreeModel(tag *XmlTag) error {
 			id := v.GetAttr("ID")
 			models, ok := p.subtreeModels[id]
 			if !ok {
-				p.subtreeModels[id] = &SubtreeModel{ports: map[string]*PortInfo{}}
+				models = &SubtreeModel{ports: map[string]*PortInfo{}}
+				p.subtreeModels[id] = models
 			}
-			for name, direction := range portMap {
-				if v.IsTag(name) {
-					n := v.GetAttr(name)
-					if n == "" {
-						return errors.New("missing attribute [name] in port (SubTree model)")
-					}
-					port := NewPortInfo(direction, n, v.GetAttr("description"))
-					port.SetDefaultValue(v.GetAttr("default"))
-					models.ports[n] = port
+			for _, portTag := range v.Children {
+				direction, ok := portMap[portTag.TagName()]
+				if !ok {
+					continue
 				}
+				n := portTag.GetAttr("name")
+				if n == "" {
+					return errors.New("missing attribute [name] in port (SubTree model)")
+				}
+				port := NewPortInfo(direction, n, portTag.GetAttr("description"))
+				port.SetDefaultValue(portTag.GetAttr("default"))
+				models.ports[n] = port
 			}
 		}
 	}
@@ -475,12 +523,13 @@ func (p *xmlParser) verify(roots []*XmlTag) error {
 	root := roots[0]
 	var model *XmlTag
 	for _, child := range root.Children {
+		if !child.IsTag("TreeNodesModel") {
+			continue
+		}
 		if model != nil {
 			return errors.New("only a single node <TreeNodesModel> is supported")
 		}
-		if child.IsTag("TreeNodesModel") {
-			model = child
-		}
+		model = child
 	}
 	if model != nil {
 		// not having a MetaModel is not an error. But consider that the
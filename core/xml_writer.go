@@ -0,0 +1,216 @@
+package core
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+)
+
+// xmlElement is the minimal DOM used to write the XML of trees and models
+type xmlElement struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*xmlElement
+}
+
+func newXmlElement(name string) *xmlElement {
+	return &xmlElement{name: name}
+}
+
+func (e *xmlElement) SetAttr(name string, value any) {
+	e.attrs = append(e.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: fmt.Sprint(value)})
+}
+
+func (e *xmlElement) AddChild(child *xmlElement) {
+	e.children = append(e.children, child)
+}
+
+func (e *xmlElement) encode(enc *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Local: e.name}, Attr: e.attrs}
+	err := enc.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	if e.text != "" {
+		err = enc.EncodeToken(xml.CharData(e.text))
+		if err != nil {
+			return err
+		}
+	}
+	for _, child := range e.children {
+		err = child.encode(enc)
+		if err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func (e *xmlElement) String() string {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "    ")
+	err := e.encode(enc)
+	if err == nil {
+		err = enc.Flush()
+	}
+	if err != nil {
+		panic(fmt.Sprintf("failed to write the XML: %v", err))
+	}
+	return buf.String()
+}
+
+func sortedKeys[K ~int | ~string, V any](m map[K]V) []K {
+	res := make([]K, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+func addNodeModelToXML(model *TreeNodeManifest, modelRoot *xmlElement) {
+	element := newXmlElement(model.Type.String())
+	element.SetAttr("ID", model.RegistrationID)
+
+	for _, portName := range sortedKeys(model.Ports) {
+		portInfo := model.Ports[portName]
+		var portElement *xmlElement
+		switch portInfo.Direction() {
+		case PortDirection_INPUT:
+			portElement = newXmlElement("input_port")
+		case PortDirection_OUTPUT:
+			portElement = newXmlElement("output_port")
+		case PortDirection_INOUT:
+			portElement = newXmlElement("inout_port")
+		}
+		portElement.SetAttr("name", portName)
+		if portInfo.IsStronglyTyped() {
+			portElement.SetAttr("type", portInfo.Type().String())
+		}
+		if portInfo.DefaultValue() != nil {
+			portElement.SetAttr("default", portInfo.DefaultValueString())
+		}
+		portElement.text = portInfo.Description()
+		element.AddChild(portElement)
+	}
+
+	if len(model.Metadata) > 0 {
+		metadataRoot := newXmlElement("MetadataFields")
+		for _, metadata := range model.Metadata {
+			for _, name := range sortedKeys(metadata) {
+				metadataElement := newXmlElement("Metadata")
+				metadataElement.SetAttr(name, metadata[name])
+				metadataRoot.AddChild(metadataElement)
+			}
+		}
+		element.AddChild(metadataRoot)
+	}
+	modelRoot.AddChild(element)
+}
+
+func addTreeNodeToXML(node ITreeNode, parent *xmlElement, addMetadata bool) {
+	config := node.Config()
+	element := newXmlElement(node.RegistrationName())
+	subtree, isSubtree := node.(interface{ SubtreeID() string })
+	if isSubtree {
+		element.SetAttr("ID", subtree.SubtreeID())
+		// by default, the name of a SubTree is its ID
+		if node.Name() != subtree.SubtreeID() {
+			element.SetAttr("name", node.Name())
+		}
+	} else {
+		element.SetAttr("name", node.Name())
+	}
+	if addMetadata {
+		element.SetAttr("_uid", node.UID())
+		element.SetAttr("_fullpath", node.FullPath())
+	}
+	for _, name := range sortedKeys(config.InputPorts) {
+		element.SetAttr(name, config.InputPorts[name])
+	}
+	for _, name := range sortedKeys(config.OutputPorts) {
+		// avoid duplicates, in the case of INOUT ports
+		if _, ok := config.InputPorts[name]; ok {
+			continue
+		}
+		element.SetAttr(name, config.OutputPorts[name])
+	}
+	for _, pre := range sortedKeys(config.PreConditions) {
+		element.SetAttr(pre.String(), config.PreConditions[pre])
+	}
+	for _, post := range sortedKeys(config.PostConditions) {
+		element.SetAttr(post.String(), config.PostConditions[post])
+	}
+	parent.AddChild(element)
+
+	// the children of a SubTree are written in their own <BehaviorTree>
+	parentNode, ok := node.(IParentNode)
+	if !ok || isSubtree {
+		return
+	}
+	for _, child := range parentNode.GetChildren() {
+		addTreeNodeToXML(child, element, addMetadata)
+	}
+}
+
+// WriteTreeNodesModelXML returns the <TreeNodesModel> of the nodes registered in the factory,
+// that is needed by the graphical editors. The builtin nodes are included only if includeBuiltin is true.
+func WriteTreeNodesModelXML(factory *BehaviorTreeFactory, includeBuiltin bool) string {
+	root := newXmlElement("root")
+	root.SetAttr("BTCPP_format", 4)
+	modelRoot := newXmlElement("TreeNodesModel")
+	root.AddChild(modelRoot)
+	for _, id := range sortedKeys(factory.Builders) {
+		if _, builtin := factory.builtinNodes[id]; builtin && !includeBuiltin {
+			continue
+		}
+		addNodeModelToXML(factory.Builders[id].TreeNodeManifest, modelRoot)
+	}
+	return root.String()
+}
+
+// WriteTreeToXML returns the XML of an instantiated tree, with one <BehaviorTree> for each subtree.
+// If addMetadata is true, every node has the attributes "_uid" and "_fullpath", and every
+// instance of a subtree has its own <BehaviorTree>, because the UIDs and paths of its nodes differ:
+// this is the format expected by Groot2. Otherwise a subtree used more than once is written only once.
+// The <TreeNodesModel> contains the models of the registered nodes; the builtin ones
+// are included only if addBuiltins is true.
+//
+// Loading the XML again creates an equivalent tree: same nodes, ports, UIDs and paths.
+func WriteTreeToXML(tree *Tree, addMetadata, addBuiltins bool) string {
+	root := newXmlElement("root")
+	root.SetAttr("BTCPP_format", 4)
+	if len(tree.Subtrees) > 0 {
+		root.SetAttr("main_tree_to_execute", tree.Subtrees[0].TreeId)
+	}
+
+	written := map[string]bool{}
+	for _, subtree := range tree.Subtrees {
+		if !addMetadata && written[subtree.TreeId] {
+			continue
+		}
+		written[subtree.TreeId] = true
+		subtreeElement := newXmlElement("BehaviorTree")
+		subtreeElement.SetAttr("ID", subtree.TreeId)
+		if addMetadata {
+			subtreeElement.SetAttr("_fullpath", subtree.InstanceName)
+		}
+		root.AddChild(subtreeElement)
+		if len(subtree.Nodes) > 0 {
+			addTreeNodeToXML(subtree.Nodes[0], subtreeElement, addMetadata)
+		}
+	}
+
+	modelRoot := newXmlElement("TreeNodesModel")
+	root.AddChild(modelRoot)
+	for _, id := range sortedKeys(tree.manifests) {
+		if _, builtin := tree.builtinNodes[id]; builtin && !addBuiltins {
+			continue
+		}
+		addNodeModelToXML(tree.manifests[id], modelRoot)
+	}
+	return root.String()
+}
@@ -0,0 +1,91 @@
+package core_test
+
+import (
+	"github.com/gorustyt/go-behavior/core"
+	"strings"
+	"testing"
+)
+
+// counterAction increments the INOUT port value
+type counterAction struct {
+	*core.SyncActionNode
+	Value int `bt:"inout,value,desc=incremented at every tick"`
+	Step  int `bt:"in,step,default=1"`
+}
+
+func (n *counterAction) Tick() core.NodeStatus {
+	n.Value += n.Step
+	return core.NodeStatus_SUCCESS
+}
+
+func TestWriteTreeToXMLRoundTrip(t *testing.T) {
+	tree, err := newRoundTripFactory(t).CreateTreeFromText(`
+<root BTCPP_format="4" main_tree_to_execute="Main">
+    <BehaviorTree ID="Main">
+        <Sequence name="root">
+            <Script code="count:=0" />
+            <SubTree ID="Increment" name="first" value="{count}" />
+            <SubTree ID="Increment" name="second" value="{count}" />
+            <Counter name="main_counter" value="{count}" step="10" _skipIf="count &gt; 100" _post="done:=true" />
+            <SubTree ID="Nested" _autoremap="true" />
+        </Sequence>
+    </BehaviorTree>
+    <BehaviorTree ID="Increment">
+        <Counter value="{value}" _while="value &lt; 1000" _onSuccess="incremented:=true" />
+    </BehaviorTree>
+    <BehaviorTree ID="Nested">
+        <Sequence>
+            <SubTree ID="Increment" value="{count}" />
+        </Sequence>
+    </BehaviorTree>
+</root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, addMetadata := range []bool{false, true} {
+		for _, addBuiltins := range []bool{false, true} {
+			first := core.WriteTreeToXML(tree, addMetadata, addBuiltins)
+			loaded, err := newRoundTripFactory(t).CreateTreeFromText(first)
+			if err != nil {
+				t.Fatalf("metadata %v, builtins %v: loading the written tree: %v\n%v", addMetadata, addBuiltins, err, first)
+			}
+			if second := core.WriteTreeToXML(loaded, addMetadata, addBuiltins); second != first {
+				t.Fatalf("metadata %v, builtins %v: the tree changed:\n%v\nwritten again as:\n%v", addMetadata, addBuiltins, first, second)
+			}
+
+			// the written tree behaves as the original one
+			if status := loaded.TickWhileRunning(); status != core.NodeStatus_SUCCESS {
+				t.Fatalf("status %v, want SUCCESS", status)
+			}
+			if count := core.BBGetOr(loaded.Subtrees[0].Blackboard, "count", -1); count != 13 {
+				t.Fatalf("count %v, want 13", count)
+			}
+		}
+	}
+
+	// the subtree used three times is written once, with its ports and conditions
+	written := core.WriteTreeToXML(tree, false, false)
+	if n := strings.Count(written, `<BehaviorTree ID="Increment">`); n != 1 {
+		t.Fatalf("Increment written %d times:\n%v", n, written)
+	}
+	for _, s := range []string{`value="{count}"`, `_skipIf="count &gt; 100"`, `_post="done:=true"`,
+		`_while="value &lt; 1000"`, `_onSuccess="incremented:=true"`, `_autoremap="true"`, `<inout_port name="value"`} {
+		if !strings.Contains(written, s) {
+			t.Errorf("%v not written:\n%v", s, written)
+		}
+	}
+}
+
+// newRoundTripFactory returns a factory with the nodes of TestWriteTreeToXMLRoundTrip
+func newRoundTripFactory(t *testing.T) *core.BehaviorTreeFactory {
+	t.Helper()
+	f := newTestFactory()
+	err := core.Register(f, "Counter", func(name string, cfg *core.NodeConfig) *counterAction {
+		return &counterAction{SyncActionNode: core.NewSyncActionNode(name, cfg)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
@@ -4,7 +4,7 @@ import "github.com/gorustyt/go-behavior/core"
 
 func init() {
 	core.SetPorts(&ConsumeQueue{}, core.InputPortWithDefaultValue("queue", &core.ProtectedQueue{}))
-	core.SetPorts(ConsumeQueue{}, core.OutputPortWithDefaultValue("popped_item", &core.ProtectedQueue{}))
+	core.SetPorts(ConsumeQueue{}, core.OutPort("popped_item"))
 
 }
 
@@ -31,21 +31,19 @@ func (n *ConsumeQueue) Tick() core.NodeStatus {
 		}
 	}
 
-	var queue *core.ProtectedQueue
-	v, err := n.GetInput("queue", queue)
+	queue, err := core.GetInput[*core.ProtectedQueue](n, "queue")
 	if err != nil {
 		panic(err)
 	}
-	queue = v.(*core.ProtectedQueue)
 	if queue != nil {
 		queue.Mtx.Lock()
-		items := queue.Items
+		items := &queue.Items
 
 		for items.Len() != 0 {
 			n.SetStatus(core.NodeStatus_RUNNING)
 			val := items.Front()
 			items.Remove(val)
-			n.SetOutput("popped_item", val)
+			n.SetOutput("popped_item", val.Value)
 			queue.Mtx.Unlock()
 			childState := n.Child().ExecuteTick()
 			queue.Mtx.Lock()
@@ -1,6 +1,7 @@
 package decorators
 
 import (
+	"fmt"
 	"github.com/gorustyt/go-behavior/core"
 	"sync"
 	"sync/atomic"
@@ -34,12 +35,11 @@ func (n *DelayNode) Halt() {
 
 func (n *DelayNode) Tick() core.NodeStatus {
 	if n.read_parameter_from_ports_ {
-		var msec_ int
-		if v, err := n.GetInput("delay_msec", &msec_); err != nil {
-			panic("Missing parameter [delay_msec] in DelayNode")
-		} else {
-			msec_ = v.(int)
+		msec, err := core.GetInput[int](n, "delay_msec")
+		if err != nil {
+			panic(fmt.Sprintf("Missing parameter [delay_msec] in DelayNode: %v", err))
 		}
+		n.msec_ = time.Duration(msec) * time.Millisecond
 	}
 
 	if !n.delay_started_ {
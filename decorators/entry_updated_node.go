@@ -0,0 +1,79 @@
+package decorators
+
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+)
+
+func init() {
+	core.SetPorts(&EntryUpdatedNode{}, core.InputPort("entry", "Entry to check"))
+}
+
+// EntryUpdatedNode ticks its child only if the entry of the blackboard was written
+// since the last time it was checked; otherwise it returns the status ifNotUpdated.
+// It is registered as "SkipUnlessUpdated" (SKIPPED) and "WaitValueUpdate" (RUNNING).
+type EntryUpdatedNode struct {
+	*core.DecoratorNode
+	ifNotUpdated        core.NodeStatus
+	entryKey            string
+	sequenceId          uint64
+	stillExecutingChild bool
+}
+
+func NewEntryUpdatedNode(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	n := &EntryUpdatedNode{
+		DecoratorNode: core.NewDecoratorNode(name, cfg),
+		ifNotUpdated:  core.NodeStatus_SKIPPED,
+	}
+	if len(args) > 0 {
+		n.ifNotUpdated = args[0].(core.NodeStatus)
+	}
+	switch n.ifNotUpdated {
+	case core.NodeStatus_SKIPPED:
+		n.SetRegistrationID("SkipUnlessUpdated")
+	case core.NodeStatus_RUNNING:
+		n.SetRegistrationID("WaitValueUpdate")
+	default:
+		panic("EntryUpdatedNode: the status returned when the entry is not updated must be SKIPPED or RUNNING")
+	}
+	return n
+}
+
+func (n *EntryUpdatedNode) Halt() {
+	n.stillExecutingChild = false
+	n.DecoratorNode.Halt()
+}
+
+func (n *EntryUpdatedNode) Tick() core.NodeStatus {
+	// continue executing an asynchronous child
+	if n.stillExecutingChild {
+		status := n.Child().ExecuteTick()
+		n.stillExecutingChild = status == core.NodeStatus_RUNNING
+		return status
+	}
+
+	if n.entryKey == "" {
+		entryStr := n.Config().InputPorts["entry"]
+		if entryStr == "" {
+			panic(fmt.Sprintf("Missing port 'entry' in [%v]", n.Name()))
+		}
+		n.entryKey = entryStr
+		if key, ok := core.IsBlackboardPointer(entryStr); ok {
+			n.entryKey = key
+		}
+	}
+
+	info, ok := n.Config().Blackboard.GetEntryInfo(n.entryKey)
+	if !ok {
+		return n.ifNotUpdated
+	}
+	previousId := n.sequenceId
+	n.sequenceId = info.SequenceId
+	if previousId == info.SequenceId {
+		return n.ifNotUpdated
+	}
+
+	status := n.Child().ExecuteTick()
+	n.stillExecutingChild = status == core.NodeStatus_RUNNING
+	return status
+}
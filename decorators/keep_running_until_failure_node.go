@@ -27,5 +27,4 @@ func (n *KeepRunningUntilFailureNode) Tick() core.NodeStatus {
 	default:
 		panic("invalid status")
 	}
-	return n.Status()
 }
@@ -2,8 +2,10 @@ package decorators
 
 import (
 	"container/list"
+	"fmt"
 	"github.com/gorustyt/go-behavior/core"
 	"reflect"
+	"strings"
 )
 
 func init() {
@@ -20,6 +22,14 @@ type LoopNode struct {
 	Type           reflect.Kind
 }
 
+// loopKindTypes are the types of the elements of a queue written in the port as a string
+var loopKindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Int:     reflect.TypeOf(0),
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Float64: reflect.TypeOf(0.0),
+	reflect.String:  reflect.TypeOf(""),
+}
+
 func NewLoopNode(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
 	return &LoopNode{Type: args[0].(reflect.Kind), DecoratorNode: core.NewDecoratorNode(name, cfg)}
 }
@@ -28,8 +38,14 @@ func (n *LoopNode) Tick() core.NodeStatus {
 	popped := false
 	if n.Status() == core.NodeStatus_IDLE {
 		n.child_running_ = false
-		// special case: the port contains a string that was converted to SharedQueue<T>
-		if n.static_queue_ != nil {
+		// special case: the port contains a string that is converted to a new queue at each loop
+		raw := n.GetRawPortValue("queue")
+		if _, ok := core.IsBlackboardPointer(raw); !ok && raw != "" {
+			queue, err := n.parseStaticQueue(raw)
+			if err != nil {
+				panic(err)
+			}
+			n.static_queue_ = queue
 			n.current_queue_ = n.static_queue_
 		}
 	}
@@ -41,7 +57,7 @@ func (n *LoopNode) Tick() core.NodeStatus {
 		var v *core.Entry
 		if n.static_queue_ == nil {
 			any_ref := n.GetLockedPortContent("queue")
-			if any_ref() != nil {
+			if any_ref != nil && any_ref() != nil {
 				v = any_ref()
 			}
 		}
@@ -59,11 +75,8 @@ func (n *LoopNode) Tick() core.NodeStatus {
 	}
 
 	if !popped && !n.child_running_ {
-		var status core.NodeStatus
-		t, err := n.GetInput("if_empty", &status)
-		if err == nil {
-			status = t.(core.NodeStatus)
-		} else {
+		status, err := core.GetInput[core.NodeStatus](n, "if_empty")
+		if err != nil {
 			panic(err)
 		}
 		return status
@@ -85,3 +98,20 @@ func (n *LoopNode) Tick() core.NodeStatus {
 	}
 	return core.NodeStatus_RUNNING
 }
+
+// parseStaticQueue converts the elements of str, separated by semicolons, to the Type of the node
+func (n *LoopNode) parseStaticQueue(str string) (*list.List, error) {
+	typ, ok := loopKindTypes[n.Type]
+	if !ok {
+		return nil, fmt.Errorf("LoopNode [%v]: the queue of type [%v] can't be read from a string", n.Name(), n.Type)
+	}
+	queue := list.New()
+	for _, part := range strings.Split(str, ";") {
+		value := reflect.New(typ)
+		if err := core.ConvFromString(strings.TrimSpace(part), value.Interface()); err != nil {
+			return nil, fmt.Errorf("LoopNode [%v]: %w", n.Name(), err)
+		}
+		queue.PushBack(value.Elem().Interface())
+	}
+	return queue, nil
+}
@@ -10,7 +10,7 @@ const (
 )
 
 func init() {
-	core.SetPorts(core.InputPortWithDefaultValue(NUM_CYCLES, 1, "Repeat a successful child up to N times. Use -1 to create an infinite loop."))
+	core.SetPorts(&RepeatNode{}, core.InputPortWithDefaultValue(NUM_CYCLES, 1, "Repeat a successful child up to N times. Use -1 to create an infinite loop."))
 }
 
 type RepeatNode struct {
@@ -37,11 +37,11 @@ func NewRepeatNode(name string, cfg *core.NodeConfig, args ...interface{}) core.
 func (n *RepeatNode) Tick() core.NodeStatus {
 
 	if n.read_parameter_from_ports_ {
-		v, err := n.GetInput(NUM_CYCLES, &n.num_cycles_)
+		numCycles, err := core.GetInput[int](n, NUM_CYCLES)
 		if err != nil {
-			panic(fmt.Sprintf("Missing parameter [%v] in RepeatNode", NUM_CYCLES))
+			panic(fmt.Sprintf("Missing parameter [%v] in RepeatNode: %v", NUM_CYCLES, err))
 		}
-		n.num_cycles_ = v.(int)
+		n.num_cycles_ = numCycles
 	}
 
 	do_loop := n.repeat_count_ < n.num_cycles_ || n.num_cycles_ == -1
@@ -6,7 +6,7 @@ import (
 )
 
 func init() {
-	core.SetPorts(core.InputPortWithDefaultValue(NUM_ATTEMPTS, 1, "Execute again a failing child up to N times. Use -1 to create an infinite loop."))
+	core.SetPorts(&RetryNode{}, core.InputPortWithDefaultValue(NUM_ATTEMPTS, 1, "Execute again a failing child up to N times. Use -1 to create an infinite loop."))
 }
 
 const (
@@ -37,17 +37,17 @@ func NewRetryNode(name string, cfg *core.NodeConfig, args ...interface{}) core.I
 
 func (n *RetryNode) Halt() {
 	n.tryCount = 0
-	n.Halt()
+	n.DecoratorNode.Halt()
 }
 
 func (n *RetryNode) Tick() core.NodeStatus {
 
 	if n.readParameterFromPorts {
-		if v, err := n.GetInput(NUM_ATTEMPTS, &n.maxAttempts); err != nil {
-			panic(fmt.Sprintf("Missing parameter [%v] in RetryNode", NUM_ATTEMPTS))
-		} else {
-			n.maxAttempts = v.(int)
+		maxAttempts, err := core.GetInput[int](n, NUM_ATTEMPTS)
+		if err != nil {
+			panic(fmt.Sprintf("Missing parameter [%v] in RetryNode: %v", NUM_ATTEMPTS, err))
 		}
+		n.maxAttempts = maxAttempts
 	}
 
 	do_loop := n.tryCount < n.maxAttempts || n.maxAttempts == -1
@@ -25,12 +25,9 @@ func NewRunOnceNode(name string, cfg *core.NodeConfig, args ...interface{}) core
 
 // ------------ implementation ----------------------------
 func (n *RunOnceNode) Tick() core.NodeStatus {
-	skip := true
-
-	if v, err := n.GetInput("then_skip", &skip); err != nil {
+	skip, err := core.GetInput[bool](n, "then_skip")
+	if err != nil {
 		panic(err)
-	} else {
-		skip = v.(bool)
 	}
 
 	if n.alreadyTicked {
@@ -1,6 +1,10 @@
 package decorators
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"errors"
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+)
 
 func init() {
 	core.SetPorts(&PreconditionNode{}, core.InputPortWithDefaultValue("if", ""))
@@ -17,18 +21,19 @@ func NewPreconditionNode(name string, cfg *core.NodeConfig, args ...interface{})
 	n := &PreconditionNode{
 		DecoratorNode: core.NewDecoratorNode(name, cfg),
 	}
-	n.loadExecutor()
+	n.SetRegistrationID("Precondition")
 	return n
 }
 func (n *PreconditionNode) Tick() core.NodeStatus {
-	n.loadExecutor()
-
-	var else_return core.NodeStatus
+	if err := n.LoadExecutor(); err != nil {
+		panic(err)
+	}
 
-	if v, err := n.GetInput("else", 0); err != nil {
-		panic("Missing parameter [else] in Precondition")
-	} else {
-		else_return = core.NodeStatus(v.(int))
+	else_return := core.NodeStatus_FAILURE
+	if v, err := core.GetInput[core.NodeStatus](n, "else"); err == nil {
+		else_return = v
+	} else if !errors.Is(err, core.ErrMissingPort) {
+		panic(fmt.Sprintf("Invalid parameter [else] in Precondition: %v", err))
 	}
 	if n._executor(n.Config().Blackboard, n.Config().Enums) {
 		child_status := n.Child().ExecuteTick()
@@ -41,22 +46,19 @@ func (n *PreconditionNode) Tick() core.NodeStatus {
 	}
 }
 
-func (n *PreconditionNode) loadExecutor() {
-	var script string
-	if v, err := n.GetInput("if", script); err != nil {
-		panic("Missing parameter [if] in Precondition")
-	} else {
-		script = v.(string)
+func (n *PreconditionNode) LoadExecutor() error {
+	script, err := core.GetInput[string](n, "if")
+	if err != nil {
+		return fmt.Errorf("missing parameter [if] in Precondition: %v", err)
 	}
-	if script == n._script {
-		return
+	if script == n._script && n._executor != nil {
+		return nil
 	}
-
-	if n._executor == nil {
-		panic("not impl")
-	} else {
-
-		n._script = script
+	executor, err := core.ParseScript(script)
+	if err != nil {
+		return err
 	}
-
+	n._executor = executor
+	n._script = script
+	return nil
 }
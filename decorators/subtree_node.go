@@ -21,6 +21,14 @@ func (n *SubTreeNode) SetSubtreeID(ID string) {
 	n.subtreeId = ID
 }
 
+func (n *SubTreeNode) SubtreeID() string {
+	return n.subtreeId
+}
+
+func (n *SubTreeNode) NodeType() core.NodeType {
+	return core.NodeType_SUBTREE
+}
+
 func (n *SubTreeNode) Tick() core.NodeStatus {
 	prevStatus := n.Status()
 	if prevStatus == core.NodeStatus_IDLE {
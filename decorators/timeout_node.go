@@ -1,6 +1,7 @@
 package decorators
 
 import (
+	"fmt"
 	"github.com/gorustyt/go-behavior/core"
 	"sync"
 	"sync/atomic"
@@ -31,13 +32,12 @@ func NewTimeoutNode(name string, cfg *core.NodeConfig, args ...interface{}) core
 }
 
 func (n *TimeoutNode) Tick() core.NodeStatus {
-	var msec_ int
 	if n.readParameterFromPorts {
-		if v, err := n.GetInput("msec", &msec_); err != nil {
-			panic("Missing parameter [msec] in TimeoutNode")
-		} else {
-			msec_ = v.(int)
+		msec, err := core.GetInput[int](n, "msec")
+		if err != nil {
+			panic(fmt.Sprintf("Missing parameter [msec] in TimeoutNode: %v", err))
 		}
+		n.msec_ = time.Duration(msec) * time.Millisecond
 	}
 	if !n.timeoutStarted.Load() {
 		n.timeoutStarted.Store(true)
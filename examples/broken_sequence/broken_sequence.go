@@ -1,81 +1,78 @@
 package main
 
-import "github.com/gorustyt/go-behavior/core"
-
-func  SayHello() core.NodeStatus {
-  printf("hello\n");
-  return NodeStatus::SUCCESS;
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"time"
+)
+
+func SayHello(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
+	fmt.Println("hello")
+	return core.NodeStatus_SUCCESS
 }
-func NewActionTestNode() *ActionTestNode {
 
+func NewActionTestNode(name string, config *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &ActionTestNode{ThreadedAction: core.NewThreadedAction(name, config)}
 }
-type  ActionTestNode : public ActionNode
-{
-public:
-  (const std::string& name) : ActionNode(name)
-  {}
-
-  NodeStatus tick() override
-  {
-    time_ = 5;
-    stop_loop_ = false;
-    int i = 0;
-    while (!stop_loop_ && i++ < time_)
-    {
-      std::this_thread::sleep_for(std::chrono::milliseconds(100));
-    }
-    return NodeStatus::SUCCESS;
-  }
-
-  virtual void halt() override
-  {
-    stop_loop_ = true;
-  }
-
-private:
-  int time_;
-  std::atomic_bool stop_loop_;
-};
-
-int main()
-{
-  BT::SequenceNode root("root");
-  BT::SimpleActionNode action1("say_hello", std::bind(SayHello));
-  ActionTestNode action2("async_action");
-
-  root.addChild(&action1);
-  root.addChild(&action2);
-
-  int count = 0;
-
-  NodeStatus status = NodeStatus::RUNNING;
-
-  while (status == NodeStatus::RUNNING)
-  {
-    status = root.executeTick();
 
-    std::cout << count++ << " : " << root.status() << " / " << action1.status() << " / "
-              << action2.status() << std::endl;
+type ActionTestNode struct {
+	*core.ThreadedAction
+}
 
-    std::this_thread::sleep_for(std::chrono::milliseconds(100));
-  }
+func (n *ActionTestNode) Tick() core.NodeStatus {
+	// Halt cancels the context of the node
+	for i := 0; i < 5 && !n.IsHaltRequested(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return core.NodeStatus_SUCCESS
+}
 
-  return 0;
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+	factory.RegisterSimpleAction("SayHello", SayHello)
+	factory.RegisterNodeType("ActionTest", NewActionTestNode)
+
+	// the nodes are created one by one and connected by hand, without the XML
+	root, err := factory.InstantiateTreeNode("root", "Sequence", &core.NodeConfig{})
+	if err != nil {
+		panic(err)
+	}
+	action1, err := factory.InstantiateTreeNode("say_hello", "SayHello", &core.NodeConfig{})
+	if err != nil {
+		panic(err)
+	}
+	action2, err := factory.InstantiateTreeNode("async_action", "ActionTest", &core.NodeConfig{})
+	if err != nil {
+		panic(err)
+	}
+
+	sequence := root.(interface{ AddChild(child core.ITreeNode) })
+	sequence.AddChild(action1)
+	sequence.AddChild(action2)
+
+	count := 0
+	status := core.NodeStatus_RUNNING
+	for status == core.NodeStatus_RUNNING {
+		status = root.ExecuteTick()
+
+		fmt.Printf("%v : %v / %v / %v\n", count, root.Status(), action1.Status(), action2.Status())
+		count++
+
+		time.Sleep(100 * time.Millisecond)
+	}
 }
-// Output
+
+// Output (Sequence doesn't tick again the children that succeeded)
 /*
 
 hello
 0 : RUNNING / SUCCESS / RUNNING
-hello
 1 : RUNNING / SUCCESS / RUNNING
-hello
 2 : RUNNING / SUCCESS / RUNNING
-hello
 3 : RUNNING / SUCCESS / RUNNING
-hello
 4 : RUNNING / SUCCESS / RUNNING
-hello
 5 : SUCCESS / IDLE / IDLE
 
 */
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gorustyt/go-behavior/core"
 	"strings"
@@ -20,39 +21,44 @@ func (n *MyLegacyMoveTo) Go(goal Point3D) bool {
 }
 
 // Similarly to the previous tutorials, we need to implement this parsing method,
-// providing a specialization of BT::convertFromString
-
-func convertFromString(key string) *Point3D {
+// registered with core.RegisterConverter
+func convertFromString(key string) (Point3D, error) {
 	// three real numbers separated by semicolons
+	var output Point3D
 	parts := strings.Split(key, ";")
-	if parts.size() != 3 {
-		panic("invalid input)")
-	} else {
-		var output Point3D
-		output.x = convertFromString < double > (parts[0])
-		output.y = convertFromString < double > (parts[1])
-		output.z = convertFromString < double > (parts[2])
-		return &output
+	if len(parts) != 3 {
+		return output, errors.New("invalid input")
+	}
+	fields := []*float64{&output.x, &output.y, &output.z}
+	for i, part := range parts {
+		value, err := core.ConvertFloat64FromString(part)
+		if err != nil {
+			return output, err
+		}
+		*fields[i] = value
 	}
+	return output, nil
 }
 
-var xml_text = `(
+var xml_text = `
  <root BTCPP_format="4">
      <BehaviorTree>
         <MoveTo  goal="-1;3;0.5" />
      </BehaviorTree>
  </root>
- )`
+`
 
 func main() {
 
 	var move_to MyLegacyMoveTo
 
 	// Here we use a lambda that captures the reference of move_to
-	MoveToWrapperWithLambda := func(parentNode *core.TreeNode, status ...core.NodeStatus) core.NodeStatus {
-		var goal Point3D
+	MoveToWrapperWithLambda := func(parentNode core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
 		// thanks to paren_node, you can access easily the input and output ports.
-		parentNode.GetInput("goal", goal)
+		goal, err := core.GetInput[Point3D](parentNode, "goal")
+		if err != nil {
+			panic(err)
+		}
 
 		res := move_to.Go(goal)
 		if res {
@@ -62,11 +68,12 @@ func main() {
 		return core.NodeStatus_FAILURE
 	}
 
+	core.RegisterConverter(convertFromString)
 	factory := core.NewBehaviorTreeFactory()
 
 	// Register the lambda with BehaviorTreeFactory::registerSimpleAction
 
-	ports := core.InputPortWithDefaultValue("goal", &Point3D{})
+	ports := core.Input[Point3D]("goal")
 	factory.RegisterSimpleAction("MoveTo", MoveToWrapperWithLambda, ports)
 
 	tree, err := factory.CreateTreeFromText(xml_text)
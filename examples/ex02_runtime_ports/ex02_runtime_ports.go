@@ -1,12 +1,12 @@
 package main
 
 import (
-    "fmt"
-    "github.com/gorustyt/go-behavior/core"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
 )
 
-// clang-format off
-var  xml_text = `(
+var xml_text = `
  <root BTCPP_format="4" >
      <BehaviorTree ID="MainTree">
         <Sequence name="root">
@@ -15,61 +15,70 @@ var  xml_text = `(
         </Sequence>
      </BehaviorTree>
  </root>
- )`
+`
 
-func NewThinkRuntimePort(name string,config *core.NodeConfig,args ...interface{})core.ITreeNode  {
-    return &ThinkRuntimePort{
-        SyncActionNode:core.NewSyncActionNode(name,config),
-    }
+func NewThinkRuntimePort(name string, config *core.NodeConfig) *ThinkRuntimePort {
+	return &ThinkRuntimePort{
+		SyncActionNode: core.NewSyncActionNode(name, config),
+	}
 }
 
 type ThinkRuntimePort struct {
-    *core.SyncActionNode
-};
-func (n*ThinkRuntimePort)Tick() core.NodeStatus {
-n.SetOutput("text", "The answer is 42");
-return core.NodeStatus_SUCCESS;
+	*core.SyncActionNode
 }
-func NewSayRuntimePort(name string,config *core.NodeConfig,args ...interface{})core.ITreeNode  {
-    return &SayRuntimePort{
-        SyncActionNode:core.NewSyncActionNode(name,config),
-    }
+
+func (n *ThinkRuntimePort) Tick() core.NodeStatus {
+	n.SetOutput("text", "The answer is 42")
+	return core.NodeStatus_SUCCESS
+}
+
+func NewSayRuntimePort(name string, config *core.NodeConfig) *SayRuntimePort {
+	return &SayRuntimePort{
+		SyncActionNode: core.NewSyncActionNode(name, config),
+	}
 }
-type  SayRuntimePort struct {
-    *core.SyncActionNode
-};
 
-// You must override the virtual function tick()
- func (n*ThinkRuntimePort)Tick()core.NodeStatus {
-auto msg = getInput<std::string>("message");
-if (!msg) {
-panic("missing required input [message]: ", msg.error());
+type SayRuntimePort struct {
+	*core.SyncActionNode
 }
-fmt.Println("Robot says: " , msg.value())
-return core.NodeStatus_SUCCESS;
+
+func (n *SayRuntimePort) Tick() core.NodeStatus {
+	msg, err := core.GetInput[string](n, "message")
+	if err != nil {
+		panic(fmt.Sprintf("missing required input [message]: %v", err))
+	}
+	fmt.Println("Robot says: " + msg)
+	return core.NodeStatus_SUCCESS
 }
-func  main() {
-  var factory core.BehaviorTreeFactory ;
 
-  //-------- register ports that might be defined at runtime --------
-  // more verbose way
-   think_ports := core.OutputPortWithDefaultValue("text","");
-  factory.RegisterBuilder(
-      CreateManifest<ThinkRuntimePort>("ThinkRuntimePort", think_ports),
-      CreateBuilder<ThinkRuntimePort>());
-  // less verbose way
-  say_ports :=core.InputPortWithDefaultValue("message","")
-  factory.RegisterNodeType("SayRuntimePort",NewSayRuntimePort, say_ports);
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
 
-  err:=factory.RegisterBehaviorTreeFromText(xml_text);
-  if err!=nil{
-      panic(err)
-  }
-  tree,err := factory.CreateTree("MainTree");
-  if err!=nil{
-      panic(err)
-  }
-  tree.TickWhileRunning();
+	//-------- register ports that might be defined at runtime --------
+	// the ports aren't declared for the types, they are given at registration
+	think_ports := core.OutputPortWithDefaultValue("text", "")
+	if err := core.Register(factory, "ThinkRuntimePort", NewThinkRuntimePort, core.WithPorts(think_ports)); err != nil {
+		panic(err)
+	}
+	say_ports := core.InputPortWithDefaultValue("message", "")
+	if err := core.Register(factory, "SayRuntimePort", NewSayRuntimePort, core.WithPorts(say_ports)); err != nil {
+		panic(err)
+	}
 
-  return
+	err := factory.RegisterBehaviorTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree, err := factory.CreateTree("MainTree")
+	if err != nil {
+		panic(err)
+	}
+	tree.TickWhileRunning()
 }
+
+/* Expected output:
+
+Robot says: The answer is 42
+
+*/
@@ -1,11 +1,18 @@
 package main
 
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
+)
+
 /* Try also
 *      <ManualSelector repeat_last_selection="1">
 *  to see the difference.
-*/
+ */
 
-var  xml_text= `(
+var xml_text = `
  <root BTCPP_format="4" >
      <BehaviorTree ID="MainTree">
         <Repeat num_cycles="3">
@@ -19,16 +26,19 @@ var  xml_text= `(
         </Repeat>
      </BehaviorTree>
  </root>
- )`
+`
 
 func main() {
-  BehaviorTreeFactory factory;
-  factory.registerNodeType<DummyNodes::SaySomething>("SaySomething");
-
-  auto tree = factory.createTreeFromText(xml_text);
-  auto ret = tree.tickWhileRunning();
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+	factory.RegisterNodeType("SaySomething", sample_nodes.NewSaySomething)
 
-  std::cout << "Result: " << ret << std::endl;
+	// the ManualSelector nodes show a menu in the terminal (controls.DefaultSelectionProvider)
+	tree, err := factory.CreateTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	ret := tree.TickWhileRunning()
 
-  return 0;
+	fmt.Printf("Result: %v\n", ret.String())
 }
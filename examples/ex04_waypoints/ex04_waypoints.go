@@ -1,9 +1,13 @@
 package main
 
 import (
-  "fmt"
-  "github.com/gorustyt/go-behavior/core"
-  "time"
+	"container/list"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/decorators"
+	"reflect"
+	"time"
 )
 
 /*
@@ -11,83 +15,80 @@ import (
  * We want to iterate through the elements of a queue, for instance a list of waypoints.
  */
 
-type   Pose2D  struct {
-   x, y, theta  float64
-};
+type Pose2D struct {
+	x, y, theta float64
+}
 
-func NewGenerateWaypoints(name string,cfg *core.NodeConfig,args ...interface{}) *GenerateWaypoints{
-  return &GenerateWaypoints{
-    SyncActionNode:core.NewSyncActionNode(name,cfg),
-  }
+func init() {
+	core.SetPorts(&GenerateWaypoints{}, core.Output[*list.List]("waypoints"))
+	core.SetPorts(&PrintNumber{}, core.Input[float64]("value"))
+	core.SetPorts(&UseWaypoint{}, core.Input[Pose2D]("waypoint"))
 }
-func (n*GenerateWaypoints)Tick()  core.NodeStatus{
-  auto shared_queue = std::make_shared<std::deque<Pose2D>>();
-  for  i := 0; i < 5; i++{
-  shared_queue->push_back(Pose2D{double(i), double(i), 0});
-  }
-  n.SetOutput("waypoints", shared_queue);
-  return core.NodeStatus_SUCCESS;
+
+func NewGenerateWaypoints(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &GenerateWaypoints{
+		SyncActionNode: core.NewSyncActionNode(name, cfg),
+	}
 }
 
-type  GenerateWaypoints struct {
+type GenerateWaypoints struct {
+	*core.SyncActionNode
+}
 
-  *core.SyncActionNode
+func (n *GenerateWaypoints) Tick() core.NodeStatus {
+	shared_queue := list.New()
+	for i := 0; i < 5; i++ {
+		shared_queue.PushBack(Pose2D{float64(i), float64(i), 0})
+	}
+	n.SetOutput("waypoints", shared_queue)
+	return core.NodeStatus_SUCCESS
+}
 
-  static PortsList providedPorts()
-  {
-    return {OutputPort<SharedQueue<Pose2D>>("waypoints")};
-  }
-};
+//--------------------------------------------------------------
 
-func NewPrintNumber(name string,cfg *core.NodeConfig,args ...interface{}) *PrintNumber{
-  return &PrintNumber{
-    SyncActionNode:core.NewSyncActionNode(name,cfg),
-  }
+func NewPrintNumber(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &PrintNumber{
+		SyncActionNode: core.NewSyncActionNode(name, cfg),
+	}
 }
-func (n*PrintNumber)Tick()  core.NodeStatus{
-  var  value float64
-  if (getInput("value", value)) {
-    fmt.Printf("PrintNumber:%v \n",value)
-    return  core.NodeStatus_SUCCESS;
-  }
-  return  core.NodeStatus_FAILURE;
+
+type PrintNumber struct {
+	*core.SyncActionNode
+}
+
+func (n *PrintNumber) Tick() core.NodeStatus {
+	value, err := core.GetInput[float64](n, "value")
+	if err != nil {
+		return core.NodeStatus_FAILURE
+	}
+	fmt.Printf("PrintNumber: %v\n", value)
+	return core.NodeStatus_SUCCESS
 }
-//--------------------------------------------------------------
-type  PrintNumber struct {
-  *core.SyncActionNode
-  static PortsList providedPorts()
-  {
-    return {InputPort<double>("value")};
-  }
-};
 
 //--------------------------------------------------------------
 
-/**
- * @brief Simple Action that uses the output of PopFromQueue<Pose2D> or ConsumeQueue<Pose2D>
- */
-func NewUseWaypoint(name string,cfg *core.NodeConfig,args ...interface{}) core.ITreeNode {
-  return &UseWaypoint{
+// UseWaypoint is a simple Action that uses the output of LoopPose
+func NewUseWaypoint(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &UseWaypoint{
+		ThreadedAction: core.NewThreadedAction(name, cfg),
+	}
+}
 
-  }
+type UseWaypoint struct {
+	*core.ThreadedAction
 }
-type  UseWaypoint struct {
-  *core.ThreadedAction
-};
-
-func (n*UseWaypoint)Tick()core.NodeStatus  {
-  var wp Pose2D
-  if (getInput("waypoint", wp)) {
-  time.Sleep(100*time.Millisecond);
-  fmt.Printf("Using waypoint:%v/%v ",  wp.x,wp.y)
-    return core.NodeStatus_SUCCESS;
-  } else{
-    return core.NodeStatus_FAILURE;
-  }
+
+func (n *UseWaypoint) Tick() core.NodeStatus {
+	wp, err := core.GetInput[Pose2D](n, "waypoint")
+	if err != nil {
+		return core.NodeStatus_FAILURE
+	}
+	time.Sleep(100 * time.Millisecond)
+	fmt.Printf("Using waypoint: %v/%v\n", wp.x, wp.y)
+	return core.NodeStatus_SUCCESS
 }
 
-// clang-format off
-var  xml_tree = `(
+var xml_tree = `
  <root BTCPP_format="4" >
      <BehaviorTree ID="TreeA">
         <Sequence>
@@ -102,26 +103,35 @@ var  xml_tree = `(
         </Sequence>
      </BehaviorTree>
  </root>
- )`
+`
 
-// clang-format on
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
 
-func  main() {
-  var factory core.BehaviorTreeFactory ;
-  
-  factory.RegisterNodeType<LoopNode<Pose2D>>("LoopPose");
+	factory.RegisterNodeType("LoopPose", decorators.NewLoopNode, reflect.Struct)
 
-  factory.RegisterNodeType<UseWaypoint>("UseWaypoint");
-  core.SetPorts(&UseWaypoint{},core.InputPortWithDefaultValue("waypoint",&Pose2D{}))
-  factory.RegisterNodeType<PrintNumber>("PrintNumber");
-  factory.RegisterNodeType<GenerateWaypoints>("GenerateWaypoints");
+	factory.RegisterNodeType("UseWaypoint", NewUseWaypoint)
+	factory.RegisterNodeType("PrintNumber", NewPrintNumber)
+	factory.RegisterNodeType("GenerateWaypoints", NewGenerateWaypoints)
 
-  tree ,err:= factory.CreateTreeFromText(xml_tree);
-if err!=nil{
-  panic(err)
+	tree, err := factory.CreateTreeFromText(xml_tree)
+	if err != nil {
+		panic(err)
+	}
+
+	tree.TickWhileRunning()
 }
 
-  tree.TickWhileRunning();
+/* Expected output:
 
-  return
-}
+PrintNumber: 1
+PrintNumber: 2
+PrintNumber: 3
+Using waypoint: 0/0
+Using waypoint: 1/1
+Using waypoint: 2/2
+Using waypoint: 3/3
+Using waypoint: 4/4
+
+*/
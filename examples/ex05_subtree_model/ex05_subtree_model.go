@@ -1,6 +1,10 @@
 package main
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+)
 
 /*
  * Demonstrate how to use a SubTree Model (since version 4.4)
@@ -20,8 +24,7 @@ import "github.com/gorustyt/go-behavior/core"
  * remapping which have no default value.
  */
 
-// clang-format off
-var xml_subtree = `(
+var xml_subtree = `
 <root BTCPP_format="4">
 
   <TreeNodesModel>
@@ -35,19 +38,19 @@ var xml_subtree = `(
 
   <BehaviorTree ID="MySub">
     <Sequence>
-      <ScriptCondition code="sub_in_value==42 && sub_in_name=='john'" />
+      <ScriptCondition code="sub_in_value==42 &amp;&amp; sub_in_name=='john'" />
       <Script code="sub_out_result:=69; sub_out_state:='ACTIVE'" />
     </Sequence>
   </BehaviorTree>
 </root>
- )`
+`
 
 /**
  * Here, when calling "MySub", only `sub_in_name` and `sub_out_state` are explicitly
  * remapped. We will use the default values for the other two.
  */
 
-var xml_maintree = `(
+var xml_maintree = `
 <root BTCPP_format="4">
 
   <BehaviorTree ID="MainTree">
@@ -55,17 +58,16 @@ var xml_maintree = `(
       <Script code="in_name:= 'john' "/>
       <SubTree ID="MySub" sub_in_name="{in_name}"
                           sub_out_state="{out_state}"/>
-      <ScriptCondition code=" out_result==69 && out_state=='ACTIVE' " />
+      <ScriptCondition code=" out_result==69 &amp;&amp; out_state=='ACTIVE' " />
     </Sequence>
   </BehaviorTree>
 
 </root>
- )`
-
-// clang-format on
+`
 
 func main() {
-	var factory core.BehaviorTreeFactory
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
 	err := factory.RegisterBehaviorTreeFromText(xml_subtree)
 	if err != nil {
 		panic(err)
@@ -78,9 +80,10 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	tree.TickWhileRunning()
+	status := tree.TickWhileRunning()
 
 	// We expect the sequence to be successful.
+	fmt.Printf("status: %v\n", status)
 
 	// The full remapping was:
 	//
@@ -21,7 +21,7 @@ func SleepMS(ms int64) {
 	time.Sleep(time.Duration(ms) * time.Millisecond)
 }
 
-func (n *CrossDoor) IsDoorClosed() core.NodeStatus {
+func (n *CrossDoor) IsDoorClosed(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
 	SleepMS(200)
 	if !n._door_open {
 		return core.NodeStatus_SUCCESS
@@ -29,7 +29,7 @@ func (n *CrossDoor) IsDoorClosed() core.NodeStatus {
 	return core.NodeStatus_FAILURE
 }
 
-func (n *CrossDoor) PassThroughDoor() core.NodeStatus {
+func (n *CrossDoor) PassThroughDoor(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
 	SleepMS(500)
 	if n._door_open {
 		return core.NodeStatus_SUCCESS
@@ -37,7 +37,7 @@ func (n *CrossDoor) PassThroughDoor() core.NodeStatus {
 	return core.NodeStatus_FAILURE
 }
 
-func (n *CrossDoor) OpenDoor() core.NodeStatus {
+func (n *CrossDoor) OpenDoor(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
 	SleepMS(500)
 	if n._door_locked {
 		return core.NodeStatus_FAILURE
@@ -47,7 +47,7 @@ func (n *CrossDoor) OpenDoor() core.NodeStatus {
 	}
 }
 
-func (n *CrossDoor) PickLock() core.NodeStatus {
+func (n *CrossDoor) PickLock(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
 	SleepMS(500)
 	// succeed at 3rd attempt
 	if n._pick_attempts > 3 {
@@ -61,14 +61,14 @@ func (n *CrossDoor) PickLock() core.NodeStatus {
 	return core.NodeStatus_FAILURE
 }
 
-func (n *CrossDoor) SmashDoor() core.NodeStatus {
+func (n *CrossDoor) SmashDoor(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
 	n._door_locked = false
 	n._door_open = true
 	// smash always works
 	return core.NodeStatus_SUCCESS
 }
 
-func (n *CrossDoor) registerNodes(factory core.BehaviorTreeFactory) {
+func (n *CrossDoor) RegisterNodes(factory *core.BehaviorTreeFactory) {
 	factory.RegisterSimpleCondition(
 		"IsDoorClosed", n.IsDoorClosed)
 
@@ -81,7 +81,7 @@ func (n *CrossDoor) registerNodes(factory core.BehaviorTreeFactory) {
 	factory.RegisterSimpleAction(
 		"PickLock", n.PickLock)
 
-	factory.RegisterSimpleCondition(
+	factory.RegisterSimpleAction(
 		"SmashDoor", n.SmashDoor)
 }
 
@@ -1,8 +1,9 @@
 package main
 
 import (
-  "github.com/gorustyt/go-behavior/core"
-  "github.com/gorustyt/go-behavior/examples/sample_nodes"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
 )
 
 /** This tutorial will teach you how basic input/output ports work.
@@ -23,10 +24,9 @@ import (
  *
  *   - Action 2 writes something into the entry of the blackboard
  *     called "the_answer".
-*/
+ */
 
-// clang-format off
-var  xml_text = `(
+var xml_text = `
 
  <root BTCPP_format="4" >
 
@@ -40,75 +40,73 @@ var  xml_text = `(
      </BehaviorTree>
 
  </root>
- )`
+`
 
-func NewThinkWhatToSay(name string,cfg*core.NodeConfig,args ...interface{})*ThinkWhatToSay  {
-return &ThinkWhatToSay{
-    SyncActionNode:core.NewSyncActionNode(name,cfg),
+func init() {
+	// A node having ports MUST declare them for its type
+	core.SetPorts(&ThinkWhatToSay{}, core.Output[string]("text"))
 }
+
+func NewThinkWhatToSay(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &ThinkWhatToSay{
+		SyncActionNode: core.NewSyncActionNode(name, cfg),
+	}
 }
-type  ThinkWhatToSay struct {
-    *core.SyncActionNode
 
+type ThinkWhatToSay struct {
+	*core.SyncActionNode
+}
 
-  // A node having ports MUST implement this STATIC method
-  static BT::PortsList providedPorts()
-  {
-    return {BT::OutputPort<std::string>("text")};
-  }
-};
 // This Action simply write a value in the port "text"
-func  (n*ThinkWhatToSay)Tick() core.NodeStatus {
-n.SetOutput("text", "The answer is 42");
-return core.NodeStatus_SUCCESS;
+func (n *ThinkWhatToSay) Tick() core.NodeStatus {
+	n.SetOutput("text", "The answer is 42")
+	return core.NodeStatus_SUCCESS
 }
-func  main() {
-
-
-  var factory core.BehaviorTreeFactory ;
-
-  // The class SaySomething has a method called providedPorts() that define the INPUTS.
-  // In this case, it requires an input called "message"
-  factory.RegisterNodeType("SaySomething",sample_nodes.NewSaySomething);
-
-  // Similarly to SaySomething, ThinkWhatToSay has an OUTPUT port called "text"
-  // Both these ports are std::string, therefore they can connect to each other
-  factory.RegisterNodeType<ThinkWhatToSay>("ThinkWhatToSay");
-
-  // SimpleActionNodes can not define their own method providedPorts(), therefore
-  // we have to pass the PortsList explicitly if we want the Action to use getInput()
-  // or setOutput();
-    say_something_ports := core.InputPortWithDefaultValue("message","");
-  factory.RegisterSimpleAction("SaySomething2", sample_nodes.SaySomethingSimple, say_something_ports);
-
-  /* An INPUT can be either a string, for instance:
-     *
-     *     <SaySomething message="hello" />
-     *
-     * or contain a "pointer" to a type erased entry in the Blackboard,
-     * using this syntax: {name_of_entry}. Example:
-     *
-     *     <SaySomething message="{the_answer}" />
-     */
-
-  tree ,err:= factory.CreateTreeFromText(xml_text);
-    if err!=nil{
-        panic(err)
-    }
-  tree.TickWhileRunning();
-
-  /*  Expected output:
-     *
-        Robot says: hello
-        Robot says: this works too
-        Robot says: The answer is 42
-    *
-    * The way we "connect" output ports to input ports is to "point" to the same
-    * Blackboard entry.
-    *
-    * This means that ThinkSomething will write into the entry with key "the_answer";
-    * SaySomething and SaySomething will read the message from the same entry.
-    *
-    */
 
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+
+	// The struct SaySomething declares with a tag its INPUT.
+	// In this case, it requires an input called "message"
+	factory.RegisterNodeType("SaySomething", sample_nodes.NewSaySomething)
+
+	// Similarly to SaySomething, ThinkWhatToSay has an OUTPUT port called "text"
+	// Both these ports are strings, therefore they can connect to each other
+	factory.RegisterNodeType("ThinkWhatToSay", NewThinkWhatToSay)
+
+	// SimpleActionNodes can not declare their own ports, therefore
+	// we have to pass the ports explicitly if we want the Action to use GetInput
+	// or SetOutput
+	say_something_ports := core.InputPortWithDefaultValue("message", "")
+	factory.RegisterSimpleAction("SaySomething2", sample_nodes.SaySomethingSimple, say_something_ports)
+
+	/* An INPUT can be either a string, for instance:
+	 *
+	 *     <SaySomething message="hello" />
+	 *
+	 * or contain a "pointer" to a type erased entry in the Blackboard,
+	 * using this syntax: {name_of_entry}. Example:
+	 *
+	 *     <SaySomething message="{the_answer}" />
+	 */
+
+	tree, err := factory.CreateTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree.TickWhileRunning()
+
+	/*  Expected output:
+
+	Robot says: hello
+	Robot says: this works too
+	Robot says: The answer is 42
+
+	The way we "connect" output ports to input ports is to "point" to the same
+	Blackboard entry.
+
+	This means that ThinkWhatToSay will write into the entry with key "the_answer";
+	SaySomething2 will read the message from the same entry.
+	*/
 }
@@ -1,80 +1,82 @@
 package main
 
 import (
-  "fmt"
-  "github.com/gorustyt/go-behavior/core"
-  "strings"
+	"errors"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"strings"
 )
 
 /* This tutorial will teach you how to deal with ports when its
- *  type is not std::string.
-*/
+ *  type is not string.
+ */
 
 // We want to be able to use this custom type
 type Position2D struct {
- x, y float64
-};
-
-// It is recommended (or, in some cases, mandatory) to define a template
-// specialization of convertFromString that converts a string to Position2D.
-func  convertFromString( str string)*Position2D {
-fmt.Printf("Converting string: \"%s\"\n", str);
-
-// real numbers separated by semicolons
- parts := strings.Split(str, ";");
-if (len(parts)!= 2) {
-panic("invalid input)");
-} else{
-var  output Position2D;
-output.x = convertFromString<double>(parts[0]);
-output.y = convertFromString<double>(parts[1]);
-return &output;
+	x, y float64
 }
-}
-func NewCalculateGoal( name string,  config *core.NodeConfig,args ...interface{}) core.ITreeNode{
-  return&CalculateGoal{SyncActionNode:core.NewSyncActionNode(name,config)}
-}
-func (n*CalculateGoal)Tick()core.NodeStatus {
-var mygoal=Position2D {1.1, 2.3};
-n.SetOutput("goal", mygoal);
-return core.NodeStatus_SUCCESS;
-}
-static PortsList providedPorts()
-{
-return {OutputPort<Position2D>("goal")};
-}
-type  CalculateGoal struct {
-  *core.SyncActionNode
 
+// It is recommended (or, in some cases, mandatory) to register with core.RegisterConverter
+// a function that converts a string to Position2D.
+func convertFromString(str string) (Position2D, error) {
+	fmt.Printf("Converting string: \"%s\"\n", str)
+
+	// real numbers separated by semicolons
+	var output Position2D
+	parts := strings.Split(str, ";")
+	if len(parts) != 2 {
+		return output, errors.New("invalid input")
+	}
+	var err error
+	if output.x, err = core.ConvertFloat64FromString(parts[0]); err != nil {
+		return output, err
+	}
+	if output.y, err = core.ConvertFloat64FromString(parts[1]); err != nil {
+		return output, err
+	}
+	return output, nil
+}
 
-};
+func init() {
+	core.RegisterConverter(convertFromString)
+	core.SetPorts(&CalculateGoal{}, core.Output[Position2D]("goal"))
+	// Optionally, a port can have a human readable description
+	core.SetPorts(&PrintTarget{}, core.Input[Position2D]("target", "Simply print the target on console..."))
+}
 
-func NewPrintTarget(name string,  config *core.NodeConfig,args ...interface{})*PrintTarget  {
-  return &PrintTarget{
-    SyncActionNode:core.NewSyncActionNode(name,config),
-  }
+func NewCalculateGoal(name string, config *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &CalculateGoal{SyncActionNode: core.NewSyncActionNode(name, config)}
 }
-func (*PrintTarget)Tick() core.NodeStatus {
- res := getInput<Position2D>("target");
-if (!res) {
-panic("error reading port [target]:", res.error());
+
+type CalculateGoal struct {
+	*core.SyncActionNode
 }
-var goal  Position2D
-fmt.Printf("Target positions: [ %.1f, %.1f ]\n", goal.x, goal.y);
-return core.NodeStatus_SUCCESS;
+
+func (n *CalculateGoal) Tick() core.NodeStatus {
+	mygoal := Position2D{1.1, 2.3}
+	n.SetOutput("goal", mygoal)
+	return core.NodeStatus_SUCCESS
 }
-type  PrintTarget struct {
-  *core.SyncActionNode
 
+func NewPrintTarget(name string, config *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &PrintTarget{
+		SyncActionNode: core.NewSyncActionNode(name, config),
+	}
+}
 
+type PrintTarget struct {
+	*core.SyncActionNode
+}
 
-  static PortsList providedPorts()
-  {
-    // Optionally, a port can have a human readable description
-    const char* description = "Simply print the target on console...";
-    return {InputPort<Position2D>("target", description)};
-  }
-};
+func (n *PrintTarget) Tick() core.NodeStatus {
+	goal, err := core.GetInput[Position2D](n, "target")
+	if err != nil {
+		panic(fmt.Sprintf("error reading port [target]: %v", err))
+	}
+	fmt.Printf("Target positions: [ %.1f, %.1f ]\n", goal.x, goal.y)
+	return core.NodeStatus_SUCCESS
+}
 
 //----------------------------------------------------------------
 
@@ -91,10 +93,9 @@ type  PrintTarget struct {
 *
 *  4) Call PrintTarget. The input "goal" will be read from the Blackboard
 *     entry "OtherGoal".
-*/
+ */
 
-// clang-format off
-var  xml_text = `(
+var xml_text = `
 
  <root BTCPP_format="4" >
      <BehaviorTree ID="MainTree">
@@ -106,28 +107,24 @@ var  xml_text = `(
         </Sequence>
      </BehaviorTree>
  </root>
- )`
-
-// clang-format on
+`
 
 func main() {
-
-
-  var factory core.BehaviorTreeFactory ;
-  factory.RegisterNodeType<CalculateGoal>("CalculateGoal");
-  factory.RegisterNodeType<PrintTarget>("PrintTarget");
-
-  tree ,err:= factory.CreateTreeFromText(xml_text);
-  if err!=nil{
-    panic(err)
-  }
-  tree.TickWhileRunning();
-
-  /* Expected output:
- *
-    Target positions: [ 1.1, 2.3 ]
-    Converting string: "-1;3"
-    Target positions: [ -1.0, 3.0 ]
-*/
-
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+	factory.RegisterNodeType("CalculateGoal", NewCalculateGoal)
+	factory.RegisterNodeType("PrintTarget", NewPrintTarget)
+
+	tree, err := factory.CreateTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree.TickWhileRunning()
+
+	/* Expected output:
+
+	Target positions: [ 1.1, 2.3 ]
+	Converting string: "-1;3"
+	Target positions: [ -1.0, 3.0 ]
+	*/
 }
@@ -1,9 +1,11 @@
 package main
 
 import (
-    "fmt"
-    "github.com/gorustyt/go-behavior/core"
-    "time"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
+	"time"
 )
 
 /** This tutorial will teach you:
@@ -11,11 +13,9 @@ import (
  *  - The difference between Sequence and ReactiveSequence
  *
  *  - How to create an asynchronous ActionNode.
-*/
+ */
 
-// clang-format off
-
-var  xml_text_sequence = `(
+var xml_text_sequence = `
 
  <root BTCPP_format="4" >
 
@@ -29,9 +29,9 @@ var  xml_text_sequence = `(
      </BehaviorTree>
 
  </root>
- )`
+`
 
-var  xml_text_reactive = `(
+var xml_text_reactive = `
 
  <root BTCPP_format="4" >
 
@@ -47,49 +47,44 @@ var  xml_text_reactive = `(
      </BehaviorTree>
 
  </root>
- )`
-
-
-func  main() {
-  var factory  core.BehaviorTreeFactory ;
-
-  factory.RegisterSimpleCondition("BatteryOK", std::bind(CheckBattery));
-  factory.RegisterNodeType<MoveBaseAction>("MoveBase");
-  factory.RegisterNodeType<SaySomething>("SaySomething");
-
-  // Compare the state transitions and messages using either
-  // xml_text_sequence and xml_text_reactive.
-
-  // The main difference that you should notice is:
-  //  1) When Sequence is used, the ConditionNode is executed only __once__ because it returns SUCCESS.
-  //  2) When ReactiveSequence is used, BatteryOK is executed at __each__ tick()
-
-  for _,xml_text :=range  []string {xml_text_sequence, xml_text_reactive}{
-    fmt.Printf("\n------------ BUILDING A NEW TREE ------------\n\n")
-     tree ,err:= factory.CreateTreeFromText(xml_text);
-    if err!=nil{
-        panic(err)
-    }
-   status := core.NodeStatus_IDLE;
-
-    // Tick the root until we receive either SUCCESS or RUNNING
-    // same as: tree.tickRoot(Tree::WHILE_RUNNING)
-    fmt.Printf("--- ticking\n")
-    status = tree.TickWhileRunning();
-    fmt.Printf("--- status:%v \n\n",status.String())
-    // If we need to run code between one tick() and the next,
-    // we can implement our own while loop
-   for (status != core.NodeStatus_SUCCESS){
-       fmt.Printf("--- ticking\n")
-      status = tree.TickOnce();
-      fmt.Printf( "--- status:%v \n\n",status)
-
-      // if still running, add some wait time
-      if (status == core.NodeStatus_RUNNING) {
-        tree.Sleep(time.Millisecond*(100));
-      }
-    }
-
-  }
-
+`
+
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+
+	factory.RegisterSimpleCondition("BatteryOK", sample_nodes.CheckBattery)
+	factory.RegisterNodeType("MoveBase", sample_nodes.NewMoveBaseAction)
+	factory.RegisterNodeType("SaySomething", sample_nodes.NewSaySomething)
+
+	// Compare the state transitions and messages using either
+	// xml_text_sequence and xml_text_reactive.
+
+	// The main difference that you should notice is:
+	//  1) When Sequence is used, the ConditionNode is executed only __once__ because it returns SUCCESS.
+	//  2) When ReactiveSequence is used, BatteryOK is executed at __each__ tick()
+
+	for _, xml_text := range []string{xml_text_sequence, xml_text_reactive} {
+		fmt.Printf("\n------------ BUILDING A NEW TREE ------------\n\n")
+		tree, err := factory.CreateTreeFromText(xml_text)
+		if err != nil {
+			panic(err)
+		}
+
+		// Tick the root until we receive either SUCCESS or FAILURE
+		// same as: tree.TickWhileRunning()
+		// If we need to run code between one tick() and the next,
+		// we can implement our own while loop
+		status := core.NodeStatus_RUNNING
+		for status == core.NodeStatus_RUNNING {
+			fmt.Printf("--- ticking\n")
+			status = tree.TickOnce()
+			fmt.Printf("--- status: %v\n\n", status)
+
+			// if still running, add some wait time
+			if status == core.NodeStatus_RUNNING {
+				tree.Sleep(100 * time.Millisecond)
+			}
+		}
+	}
 }
@@ -1,6 +1,11 @@
 package main
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
+)
 
 /** This is a more complex example that uses Fallback,
  * Decorators and Subtrees
@@ -8,9 +13,7 @@ import "github.com/gorustyt/go-behavior/core"
  * For the sake of simplicity, we aren't focusing on ports remapping to the time being.
  */
 
-// clang-format off
-
-var  xml_text = `(
+var xml_text = `
 <root BTCPP_format="4">
 
     <BehaviorTree ID="MainTree">
@@ -36,32 +39,30 @@ var  xml_text = `(
     </BehaviorTree>
 
 </root>
- )`
-
-// clang-format on
+`
 
 func main() {
-  var  factory core.BehaviorTreeFactory;
-
-  var cross_door  CrossDoor ;
-  cross_door.RegisterNodes(factory);
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
 
-  // In this example a single XML contains multiple <BehaviorTree>
-  // To determine which one is the "main one", we should first register
-  // the XML and then allocate a specific tree, using its ID
+	cross_door := sample_nodes.NewCrossDoor()
+	cross_door.RegisterNodes(factory)
 
-  err:=factory.RegisterBehaviorTreeFromText(xml_text);
-  if err!=nil{
-      panic(err)
-  }
-  tree ,err:= factory.CreateTree("MainTree");
-    if err!=nil{
-        panic(err)
-    }
-  // helper function to print the tree
-  BT::printTreeRecursively(tree.rootNode());
+	// In this example a single XML contains multiple <BehaviorTree>
+	// To determine which one is the "main one", we should first register
+	// the XML and then allocate a specific tree, using its ID
 
-  // Tick multiple times, until either FAILURE of SUCCESS is returned
-  tree.TickWhileRunning();
+	err := factory.RegisterBehaviorTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree, err := factory.CreateTree("MainTree")
+	if err != nil {
+		panic(err)
+	}
+	// helper function to print the tree
+	fmt.Println(core.WriteTreeToXML(tree, false, false))
 
+	// Tick multiple times, until either FAILURE of SUCCESS is returned
+	tree.TickWhileRunning()
 }
@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
 	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
 )
 
 /** In the CrossDoor example we did not exchange any information
@@ -19,9 +21,7 @@ import (
  *
  */
 
-// clang-format off
-
-var xml_text = `(
+var xml_text = `
 <root BTCPP_format="4">
 
     <BehaviorTree ID="MainTree">
@@ -45,13 +45,14 @@ var xml_text = `(
     </BehaviorTree>
 
 </root>
- )`
+`
 
 func main() {
-	var factory core.BehaviorTreeFactory
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
 
-	factory.RegisterNodeType < SaySomething > ("SaySomething")
-	factory.RegisterNodeType < MoveBaseAction > ("MoveBase")
+	factory.RegisterNodeType("SaySomething", sample_nodes.NewSaySomething)
+	factory.RegisterNodeType("MoveBase", sample_nodes.NewMoveBaseAction)
 
 	err := factory.RegisterBehaviorTreeFromText(xml_text)
 	if err != nil {
@@ -64,20 +65,26 @@ func main() {
 	tree.TickWhileRunning()
 
 	// let's visualize some information about the current state of the blackboards.
-	fmt.Printf("\n------ First BB ------")
+	fmt.Printf("\n------ First BB ------\n")
 	tree.Subtrees[0].Blackboard.DebugMessage()
-	fmt.Printf("\n------ Second BB------")
+	fmt.Printf("\n------ Second BB------\n")
 	tree.Subtrees[1].Blackboard.DebugMessage()
 
 }
 
 /* Expected output:
 
+[ MoveBase: SEND REQUEST ]. goal: x=1.0 y=2.0 theta=3.0
+[ MoveBase: FINISHED ]
+Robot says: goal reached
+
 ------ First BB ------
-move_result (std::string)
-move_goal (Pose2D)
+move_goal (sample_nodes.Pose2D)
+move_result (string)
 
 ------ Second BB------
+result (string)
+target (sample_nodes.Pose2D)
 [result] remapped to port of parent tree [move_result]
 [target] remapped to port of parent tree [move_goal]
 
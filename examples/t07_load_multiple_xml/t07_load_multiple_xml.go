@@ -1,8 +1,10 @@
 package main
 
 import (
-    "fmt"
-    "github.com/gorustyt/go-behavior/core"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
 )
 
 /** This example show how it is possible to:
@@ -10,9 +12,7 @@ import (
  * - instantiate a specific tree, instead of the one specified by [main_tree_to_execute]
  */
 
-// clang-format off
-
-var  xml_text_main =`(
+var xml_text_main = `
 <root BTCPP_format="4">
     <BehaviorTree ID="MainTree">
         <Sequence>
@@ -21,60 +21,61 @@ var  xml_text_main =`(
             <SubTree ID="SubB"/>
         </Sequence>
     </BehaviorTree>
-</root>  )";
+</root>
+`
 
-static const char* xml_text_subA = R"(
+var xml_text_subA = `
 <root BTCPP_format="4">
     <BehaviorTree ID="SubA">
         <SaySomething message="Executing SubA" />
     </BehaviorTree>
-</root>  )`
+</root>
+`
 
-var  xml_text_subB = `(
+var xml_text_subB = `
 <root BTCPP_format="4">
     <BehaviorTree ID="SubB">
         <SaySomething message="Executing SubB" />
     </BehaviorTree>
-</root>  )`
-
-// clang-format on
-
-
+</root>
+`
 
 func main() {
-  var factory core.BehaviorTreeFactory ;
-  factory.RegisterNodeType<DummyNodes::SaySomething>("SaySomething");
-
-  // Register the behavior tree definitions, but do not instantiate them yet.
-  // Order is not important.
-  factory.RegisterBehaviorTreeFromText(xml_text_subA);
-  factory.RegisterBehaviorTreeFromText(xml_text_subB);
-  factory.RegisterBehaviorTreeFromText(xml_text_main);
-
-  //Check that the BTs have been registered correctly
-  fmt.Printf("Registered BehaviorTrees:")
-  for  _,bt_name :=range  factory.RegisteredBehaviorTrees(){
-      fmt.Printf(" -%v\n ",bt_name )
-  }
-
-  // You can create the MainTree and the subtrees will be added automatically.
-  fmt.Println("----- MainTree tick ----")
-  main_tree ,err:= factory.CreateTree("MainTree");
-  if err!=nil{
-      panic(err)
-  }
-  main_tree.TickWhileRunning();
-
-  // ... or you can create only one of the subtree
-  fmt.Println("----- SubA tick ----")
-
-  subA_tree,err := factory.CreateTree("SubA");
-  if err!=nil{
-      panic(err)
-  }
-  subA_tree.TickWhileRunning();
-
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+	factory.RegisterNodeType("SaySomething", sample_nodes.NewSaySomething)
+
+	// Register the behavior tree definitions, but do not instantiate them yet.
+	// Order is not important.
+	for _, xml_text := range []string{xml_text_subA, xml_text_subB, xml_text_main} {
+		if err := factory.RegisterBehaviorTreeFromText(xml_text); err != nil {
+			panic(err)
+		}
+	}
+
+	// Check that the BTs have been registered correctly
+	fmt.Println("Registered BehaviorTrees:")
+	for _, bt_name := range factory.RegisteredBehaviorTrees() {
+		fmt.Printf(" - %v\n", bt_name)
+	}
+
+	// You can create the MainTree and the subtrees will be added automatically.
+	fmt.Println("----- MainTree tick ----")
+	main_tree, err := factory.CreateTree("MainTree")
+	if err != nil {
+		panic(err)
+	}
+	main_tree.TickWhileRunning()
+
+	// ... or you can create only one of the subtree
+	fmt.Println("----- SubA tick ----")
+	subA_tree, err := factory.CreateTree("SubA")
+	if err != nil {
+		panic(err)
+	}
+	subA_tree.TickWhileRunning()
 }
+
 /* Expected output:
 
 Registered BehaviorTrees:
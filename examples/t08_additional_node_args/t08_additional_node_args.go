@@ -1,19 +1,20 @@
 package main
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+)
 
 // To demonstrate how to pass arguments by reference, we
-// use a simple non-copyable object
-class NoCopyObj {
-public:
-  NoCopyObj(int val): _value(val) {}
-
-  NoCopyObj(const NoCopyObj& ) = delete;
-  NoCopyObj& operator =(const NoCopyObj& ) = delete;
-  int value() { return _value; }
-private:
-  int _value = 0;
-};
+// use an object shared through a pointer
+type NoCopyObj struct {
+	value int
+}
+
+func (o *NoCopyObj) Value() int {
+	return o.value
+}
 
 /*
  * Sometimes, it is convenient to pass additional (static) arguments to a Node.
@@ -24,65 +25,54 @@ private:
  * additional arguments.
  */
 
-// Action_A has a different constructor than the default one.
-class Action_A : public SyncActionNode
-{
-public:
-  // additional arguments passed to the constructor
-  Action_A(const std::string& name, const NodeConfig& config, int arg_int,
-           std::string arg_str, NoCopyObj& nc) :
-    SyncActionNode(name, config), _arg1(arg_int), _arg2(arg_str), _nc(nc)
-  {}
-
-  NodeStatus tick() override
-  {
-    std::cout << name() << ": " << _arg1 << " / "
-              << _arg2 << " / " << _nc.value() << std::endl;
-    return NodeStatus::SUCCESS;
-  }
-  static PortsList providedPorts()
-  {
-    return {};
-  }
-
-private:
-  int _arg1;
-  std::string _arg2;
-  NoCopyObj& _nc;
-};
-
-// Action_B implements an init(...) method that must be called once at the beginning.
-class Action_B : public SyncActionNode
-{
-public:
-  Action_B(const std::string& name, const NodeConfig& config) :
-    SyncActionNode(name, config)
-  {}
-
-  // we want this method to be called ONCE and BEFORE the first tick()
-  void initialize(int arg_int, std::string arg_str)
-  {
-    _arg1 = (arg_int);
-    _arg2 = (arg_str);
-  }
-
-  NodeStatus tick() override
-  {
-    std::cout << name() << ": " << _arg1 << " / " << _arg2 << std::endl;
-    return NodeStatus::SUCCESS;
-  }
-  static PortsList providedPorts()
-  {
-    return {};
-  }
-
-private:
-  int _arg1;
-  std::string _arg2;
-};
+// Action_A receives the additional arguments passed to RegisterNodeType in its constructor.
+func NewAction_A(name string, config *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &Action_A{
+		SyncActionNode: core.NewSyncActionNode(name, config),
+		_arg1:          args[0].(int),
+		_arg2:          args[1].(string),
+		_nc:            args[2].(*NoCopyObj),
+	}
+}
+
+type Action_A struct {
+	*core.SyncActionNode
+	_arg1 int
+	_arg2 string
+	_nc   *NoCopyObj
+}
+
+func (n *Action_A) Tick() core.NodeStatus {
+	fmt.Printf("%v: %v / %v / %v\n", n.Name(), n._arg1, n._arg2, n._nc.Value())
+	return core.NodeStatus_SUCCESS
+}
+
+// Action_B implements an Initialize(...) method that must be called once at the beginning.
+func NewAction_B(name string, config *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &Action_B{
+		SyncActionNode: core.NewSyncActionNode(name, config),
+	}
+}
+
+type Action_B struct {
+	*core.SyncActionNode
+	_arg1 int
+	_arg2 string
+}
+
+// we want this method to be called ONCE and BEFORE the first tick()
+func (n *Action_B) Initialize(arg_int int, arg_str string) {
+	n._arg1 = arg_int
+	n._arg2 = arg_str
+}
+
+func (n *Action_B) Tick() core.NodeStatus {
+	fmt.Printf("%v: %v / %v\n", n.Name(), n._arg1, n._arg2)
+	return core.NodeStatus_SUCCESS
+}
 
 // Simple tree, used to execute once each action.
-var  xml_text = `(
+var xml_text = `
 
  <root BTCPP_format="4">
      <BehaviorTree>
@@ -92,38 +82,39 @@ var  xml_text = `(
         </Sequence>
      </BehaviorTree>
  </root>
- )`
+`
 
 func main() {
-  var factory core.BehaviorTreeFactory ;
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+
+	non_copyable := &NoCopyObj{value: 88}
 
-  NoCopyObj non_copyable(88);
+	// Passing the extra parameters to the constructor of Action_A.
+	// Pass a pointer to share an object, instead of a copy.
+	factory.RegisterNodeType("Action_A", NewAction_A, 42, "hello world", non_copyable)
 
-  // Passing the extra parameters to the constructor of Action_A
-  // note that if you want to pass an object by ref, instead of value
-  // (copy), you must use std::ref wrapper.
-  factory.registerNodeType<Action_A>("Action_A", 42, "hello world",
-                                     std::ref(non_copyable));
+	// Action_B will require initialization
+	factory.RegisterNodeType("Action_B", NewAction_B)
 
-  // Action_B will require initialization
-  factory.registerNodeType<Action_B>("Action_B");
+	tree, err := factory.CreateTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
 
-  auto tree = factory.createTreeFromText(xml_text);
+	visitor := func(node core.ITreeNode) {
+		if action_B_node, ok := node.(*Action_B); ok {
+			action_B_node.Initialize(69, "interesting_value")
+		}
+	}
+	// apply the visitor to all the nodes of the tree
+	tree.ApplyVisitor(visitor)
 
-  auto visitor = [](TreeNode* node) {
-    if (auto action_B_node = dynamic_cast<Action_B*>(node))
-    {
-      action_B_node->initialize(69, "interesting_value");
-    }
-  };
-  // apply the visitor to all the nodes of the tree
-  tree.applyVisitor(visitor);
+	tree.TickWhileRunning()
 
-  tree.tickWhileRunning();
+	/* Expected output:
 
-  /* Expected output:
-        Action_A: 42 / hello world / 88
-        Action_B: 69 / interesting_value
-  */
-  return 0;
+	Action_A: 42 / hello world / 88
+	Action_B: 69 / interesting_value
+	*/
 }
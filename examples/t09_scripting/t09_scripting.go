@@ -1,15 +1,18 @@
 package main
 
-import "github.com/gorustyt/go-behavior/core"
+import (
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
+)
 
-// clang-format off
-    var xml_text = `(
+var xml_text = `
  <root BTCPP_format="4">
      <BehaviorTree>
         <Sequence>
             <Script code=" msg:='hello world' " />
             <Script code=" A:=THE_ANSWER; B:=3.14; color:=RED " />
-            <Precondition if="A>B && color != BLUE" else="FAILURE">
+            <Precondition if="A>B &amp;&amp; color != BLUE" else="FAILURE">
                 <Sequence>
                   <SaySomething message="{A}"/>
                   <SaySomething message="{B}"/>
@@ -20,38 +23,41 @@ import "github.com/gorustyt/go-behavior/core"
         </Sequence>
      </BehaviorTree>
  </root>
- )`
+`
+
+type Color int
 
-// clang-format on
 const (
-    RED = 1
-        BLUE = 2
-    GREEN = 3
+	RED Color = iota + 1
+	BLUE
+	GREEN
 )
-func  main() {
-  var factory core.BehaviorTreeFactory ;
-  factory.RegisterNodeType<DummyNodes::SaySomething>("SaySomething");
-
 
-  // We can add these enums to the scripting language
-  factory.RegisterScriptingEnums<Color>();
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+	factory.RegisterNodeType("SaySomething", sample_nodes.NewSaySomething)
 
-  // Or we can do it manually
-  factory.RegisterScriptingEnum("THE_ANSWER", 42);
+	// We can add these enums to the scripting language
+	for name, value := range map[string]Color{"RED": RED, "BLUE": BLUE, "GREEN": GREEN} {
+		factory.RegisterScriptingEnum(name, int(value))
+	}
 
-   tree ,err:= factory.CreateTreeFromText(xml_text);
-   if err!=nil{
-       panic(err)
-   }
-  tree.TickWhileRunning();
+	// Or we can do it manually
+	factory.RegisterScriptingEnum("THE_ANSWER", 42)
 
+	tree, err := factory.CreateTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree.TickWhileRunning()
 }
 
 /* Expected output:
 
-Robot says: 42.000000
-Robot says: 3.140000
+Robot says: 42
+Robot says: 3.14
 Robot says: hello world
-Robot says: 1.000000
+Robot says: 1
 
 */
@@ -1,8 +1,11 @@
 package main
 
 import (
-    "fmt"
-    "github.com/gorustyt/go-behavior/core"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/loggers"
+	"sort"
 )
 
 /** Show the use of the TreeObserver.
@@ -10,7 +13,7 @@ import (
 
 // clang-format off
 
-var  xml_text = `(
+var xml_text = `
 <root BTCPP_format="4">
 
     <BehaviorTree ID="MainTree">
@@ -36,58 +39,58 @@ var  xml_text = `(
     </BehaviorTree>
 
 </root>
- )`
+`
 
 // clang-format on
 
 func main() {
-  var factory core.BehaviorTreeFactory ;
-
- err:= factory.RegisterBehaviorTreeFromText(xml_text);
- if err!=nil{
-     panic(err)
- }
-   tree ,err:= factory.CreateTree("MainTree");
-    if err!=nil{
-        panic(err)
-    }
-  // Helper function to print the tree.
-  BT::printTreeRecursively(tree.rootNode());
-
-  // The purpose of the observer is to save some statistics about the number of times
-  // a certain node returns SUCCESS or FAILURE.
-  // This is particularly useful to create unit tests and to check if
-  // a certain set of transitions happened as expected
-  BT::TreeObserver observer(tree);
-
-  // Print the unique ID and the corresponding human readable path
-  // Path is also expected to be unique.
-  ordered_UID_to_path:=map[uint16]string{}
-  for name, uid:=range  observer.pathToUID() {
-    ordered_UID_to_path[uid] = name;
-  }
-
-  for uid, name:=range  ordered_UID_to_path{
-    std::cout << uid << " -> " << name << std::endl;
-  }
-
-
-  tree.TickWhileRunning();
-
-  // You can access a specific statistic, using is full path or the UID
-  const auto& last_action_stats = observer.getStatistics("last_action");
-  assert(last_action_stats.transitions_count > 0);
-  fmt.Println("----------------" )
-  // print all the statistics
-  for uid, name:=range ordered_UID_to_path {
-    const auto& stats = observer.getStatistics(uid);
-
-    std::cout << "[" << name
-              << "] \tT/S/F:  " << stats.transitions_count
-              << "/" << stats.success_count
-              << "/" << stats.failure_count
-              << std::endl;
-  }
-
-  return 0;
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+
+	err := factory.RegisterBehaviorTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree, err := factory.CreateTree("MainTree")
+	if err != nil {
+		panic(err)
+	}
+
+	// The purpose of the observer is to save some statistics about the number of times
+	// a certain node returns SUCCESS or FAILURE.
+	// This is particularly useful to create unit tests and to check if
+	// a certain set of transitions happened as expected
+	observer := loggers.NewTreeObserver(tree)
+
+	// Print the unique ID and the corresponding human readable path
+	// Path is also expected to be unique.
+	uidToPath := observer.UIDToPath()
+	var uids []uint16
+	for uid := range uidToPath {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+
+	for _, uid := range uids {
+		fmt.Printf("%v -> %v\n", uid, uidToPath[uid])
+	}
+
+	tree.TickWhileRunning()
+
+	// You can access a specific statistic, using is full path or the UID
+	lastActionStats, err := observer.GetStatisticsByPath("last_action")
+	if err != nil {
+		panic(err)
+	}
+	if lastActionStats.TransitionsCount == 0 {
+		panic("last_action was never executed")
+	}
+
+	fmt.Println("----------------")
+	// print all the statistics
+	for _, uid := range uids {
+		stats, _ := observer.GetStatistics(uid)
+		fmt.Printf("[%v] \tT/S/F:  %v/%v/%v\n", uidToPath[uid],
+			stats.TransitionsCount, stats.SuccessCount, stats.FailureCount)
+	}
 }
@@ -1,14 +1,17 @@
 package main
 
 import (
-  "fmt"
-  "github.com/gorustyt/go-behavior/core"
-  "time"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/examples/sample_nodes"
+	"os"
+	"time"
 )
 
 // clang-format off
 
-var  xml_text = `(
+var xml_text = `
 <root BTCPP_format="4">
 
   <BehaviorTree ID="MainTree">
@@ -33,9 +36,9 @@ var  xml_text = `(
   </BehaviorTree>
 
 </root>
- )`
+`
 
-var  json_text = `(
+var json_text = `
 {
   "TestNodeConfigs": {
     "MyTest": {
@@ -46,82 +49,84 @@ var  json_text = `(
   },
 
   "SubstitutionRules": {
-    "mysub/action_*": "TestAction",
+    "mysub/action_*": "DummyAction",
     "talk": "TestSaySomething",
     "last_action": "MyTest"
   }
 }
- )`
+`
 
 // clang-format on
 
 func main() {
-
- var  factory core.BehaviorTreeFactory ;
-
-  factory.RegisterNodeType<SaySomething>("SaySomething");
-
-  // We use lambdas and registerSimpleAction, to create
-  // a "dummy" node, that we want to create instead of a given one.
-
-  // Simple node that just prints its name and return SUCCESS
-  factory.RegisterSimpleAction("DummyAction", func(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
-  fmt.Printf("DummyAction substituting:%v ",node.Name())
-    return core.NodeStatus_SUCCESS;
-  });
-
-  // Action that is meant to substitute SaySomething.
-  // It will try to use the input port "message"
-  factory.RegisterSimpleAction("TestSaySomething", func(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
-    auto msg = self.getInput<std::string>("message");
-    if (!msg) {
-    throw BT::RuntimeError( "missing required input [message]: ", msg.error() );
-    }
-    std::cout << "TestSaySomething: " << msg.value() << std::endl;
-    return core.NodeStatus_SUCCESS;
-  });
-
-  //----------------------------
-  // pass "no_sub" as first argument to avoid adding rules
-  bool skip_substitution = (argc == 2) && std::string(argv[1]) == "no_sub";
-
-  if(!skip_substitution) {
-    // we can use a JSON file to configure the substitution rules
-    // or do it manually
-   USE_JSON := true;
-
-    if(USE_JSON) {
-      factory.loadSubstitutionRuleFromJSON(json_text);
-    } else {
-      // Substitute nodes which match this wildcard pattern with TestAction
-      factory.addSubstitutionRule("mysub/action_*", "TestAction");
-
-      // Substitute the node with name [talk] with TestSaySomething
-      factory.addSubstitutionRule("talk", "TestSaySomething");
-
-      // This configuration will be passed to a TestNode
-     var  test_config core.TestNodeConfig
-      // Convert the node in asynchronous and wait 2000 ms
-      test_config.AsyncDelay = (2000)*time.Millisecond;
-      // Execute this postcondition, once completed
-      test_config.PostScript = "msg ='message SUBSTITUED'";
-
-      // Substitute the node with name [last_action] with a TestNode,
-      // configured using test_config
-      factory.AddSubstitutionRule("last_action", test_config);
-    }
-  }
-
-  err:=factory.RegisterBehaviorTreeFromText(xml_text);
-  if err!=nil{
-      panic(err)
-  }
-  // During the construction phase of the tree, the substitution
-  // rules will be used to instantiate the test nodes, instead of the
-  // original ones.
- tree,err := factory.CreateTree("MainTree");
- if err!=nil{
-   panic(err)
- }
-  tree.TickWhileRunning();
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+
+	factory.RegisterNodeType("SaySomething", sample_nodes.NewSaySomething)
+
+	// We use lambdas and RegisterSimpleAction, to create
+	// a "dummy" node, that we want to create instead of a given one.
+
+	// Simple node that just prints its name and return SUCCESS
+	factory.RegisterSimpleAction("DummyAction", func(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
+		fmt.Printf("DummyAction substituting: %v\n", node.Name())
+		return core.NodeStatus_SUCCESS
+	})
+
+	// Action that is meant to substitute SaySomething.
+	// It will try to use the input port "message"
+	factory.RegisterSimpleAction("TestSaySomething", func(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
+		msg, err := core.GetInput[string](node, "message")
+		if err != nil {
+			panic(fmt.Sprintf("missing required input [message]: %v", err))
+		}
+		fmt.Printf("TestSaySomething: %v\n", msg)
+		return core.NodeStatus_SUCCESS
+	}, core.Input[string]("message"))
+
+	//----------------------------
+	// pass "no_sub" as first argument to avoid adding rules
+	skipSubstitution := len(os.Args) == 2 && os.Args[1] == "no_sub"
+
+	if !skipSubstitution {
+		// we can use a JSON file to configure the substitution rules
+		// or do it manually
+		useJson := true
+
+		if useJson {
+			if err := factory.LoadSubstitutionRulesFromJSON(json_text); err != nil {
+				panic(err)
+			}
+		} else {
+			// Substitute nodes which match this wildcard pattern with DummyAction
+			factory.AddSubstitutionRule("mysub/action_*", "DummyAction")
+
+			// Substitute the node with name [talk] with TestSaySomething
+			factory.AddSubstitutionRule("talk", "TestSaySomething")
+
+			// This configuration will be passed to a TestNode
+			testConfig := core.NewTestNodeConfig()
+			// Convert the node in asynchronous and wait 2000 ms
+			testConfig.AsyncDelay = 2000 * time.Millisecond
+			// Execute this postcondition, once completed
+			testConfig.PostScript = "msg ='message SUBSTITUED'"
+
+			// Substitute the node with name [last_action] with a TestNode,
+			// configured using testConfig
+			factory.AddSubstitutionRule("last_action", testConfig)
+		}
+	}
+
+	err := factory.RegisterBehaviorTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	// During the construction phase of the tree, the substitution
+	// rules will be used to instantiate the test nodes, instead of the
+	// original ones.
+	tree, err := factory.CreateTree("MainTree")
+	if err != nil {
+		panic(err)
+	}
+	tree.TickWhileRunning()
 }
@@ -1,40 +1,86 @@
 package main
 
+import (
+	"errors"
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"strings"
+)
+
 // my custom type
 type Vector4D struct {
-   w float64
-   x float64
-   y float64
-   z float64
-};
+	W float64
+	X float64
+	Y float64
+	Z float64
+}
 
 // add this just in case, if it is necessary to register it with
-// Groot2 publisher.
-// You will need to add `RegisterJsonDefinition<Vector4D>(ToJson);` in you main
-func  ToJson( dest map[string]interface{},  pose Vector4D){
-  dest["w"] = pose.w;
-  dest["x"] = pose.x;
-  dest["y"] = pose.y;
-  dest["z"] = pose.z;
-}
-
-namespace BT {
-
-template <> inline
-    Vector4D convertFromString(StringView key)
-{
-  const auto parts = BT::splitString(key, ',');
-  if (parts.size() != 4)
-  {
-    throw BT::RuntimeError("invalid input)");
-  }
-
-  Vector4D output;
-  output.w = convertFromString<double>(parts[0]);
-  output.x     = convertFromString<double>(parts[1]);
-  output.y     = convertFromString<double>(parts[2]);
-  output.z = convertFromString<double>(parts[3]);
-  return output;
+// Groot2 publisher: core.RegisterJsonDefinition(ToJson, nil)
+func ToJson(dest map[string]any, pose *Vector4D) {
+	dest["w"] = pose.W
+	dest["x"] = pose.X
+	dest["y"] = pose.Y
+	dest["z"] = pose.Z
+}
+
+// ParseVector4D parses the format "w,x,y,z"; Vector4D doesn't need to implement core.IFromStr
+func ParseVector4D(key string) (Vector4D, error) {
+	var output Vector4D
+	parts := strings.Split(key, ",")
+	if len(parts) != 4 {
+		return output, errors.New("invalid input")
+	}
+	fields := []*float64{&output.W, &output.X, &output.Y, &output.Z}
+	for i, part := range parts {
+		value, err := core.ConvertFloat64FromString(strings.TrimSpace(part))
+		if err != nil {
+			return output, err
+		}
+		*fields[i] = value
+	}
+	return output, nil
 }
 
+func init() {
+	// also used for the ports of type []Vector4D, e.g. "1,2,3,4;5,6,7,8"
+	core.RegisterConverter(ParseVector4D)
+}
+
+type PrintVectors struct {
+	*core.SyncActionNode
+	Vectors []Vector4D `bt:"in,value"`
+}
+
+func NewPrintVectors(name string, cfg *core.NodeConfig) *PrintVectors {
+	return &PrintVectors{SyncActionNode: core.NewSyncActionNode(name, cfg)}
+}
+
+func (n *PrintVectors) Tick() core.NodeStatus {
+	for _, v := range n.Vectors {
+		fmt.Printf("x:%f  y:%f  z:%f  w:%f\n", v.X, v.Y, v.Z, v.W)
+	}
+	return core.NodeStatus_SUCCESS
+}
+
+var xml_text = `
+<root BTCPP_format="4">
+    <BehaviorTree ID="MainTree">
+        <PrintVectors value="1,2,3,4;5,6,7,8"/>
+    </BehaviorTree>
+</root>
+`
+
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+	if err := core.Register(factory, "PrintVectors", NewPrintVectors); err != nil {
+		panic(err)
+	}
+	tree, err := factory.CreateTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree.TickWhileRunning()
 }
@@ -0,0 +1,62 @@
+// The node PrintVector, loaded by t13_plugin_executor. Build the plugin with:
+//
+//	go build -buildmode=plugin -o t13_plugin_action.so ./examples/t13_plugin_action
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"strings"
+)
+
+type Vector4D struct {
+	W, X, Y, Z float64
+}
+
+// FromString parses the format "w,x,y,z"
+func (v *Vector4D) FromString(str string) error {
+	parts := strings.Split(str, ",")
+	if len(parts) != 4 {
+		return errors.New("invalid input")
+	}
+	fields := []*float64{&v.W, &v.X, &v.Y, &v.Z}
+	for i, part := range parts {
+		value, err := core.ConvertFloat64FromString(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		*fields[i] = value
+	}
+	return nil
+}
+
+func init() {
+	core.SetPorts(&PrintVector{}, core.Input[Vector4D]("value"))
+}
+
+type PrintVector struct {
+	*core.SyncActionNode
+}
+
+func NewPrintVector(name string, cfg *core.NodeConfig, args ...interface{}) core.ITreeNode {
+	return &PrintVector{SyncActionNode: core.NewSyncActionNode(name, cfg)}
+}
+
+func (n *PrintVector) Tick() core.NodeStatus {
+	// the strings are parsed with Vector4D.FromString
+	v, err := core.GetInput[Vector4D](n, "value")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("x:%f  y:%f  z:%f  w:%f\n", v.X, v.Y, v.Z, v.W)
+	return core.NodeStatus_SUCCESS
+}
+
+// RegisterNodes is called by BehaviorTreeFactory.RegisterFromPlugin, when loading the plugin.
+func RegisterNodes(factory *core.BehaviorTreeFactory) {
+	factory.RegisterNodeType("PrintVector", NewPrintVector)
+}
+
+// main is not used when the package is built as a plugin
+func main() {}
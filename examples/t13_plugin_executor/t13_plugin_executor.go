@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"os"
+)
+
+var xml_text = `
+ <root BTCPP_format="4" main_tree_to_execute="MainTree" >
+  <BehaviorTree ID="MainTree">
+    <Sequence>
+        <Script   code="vect:='1,2,3,4'"/>
+        <PrintVector value="{vect}"/>
+        <SubTree ID="MySub" v4="{vect}"/>
+    </Sequence>
+  </BehaviorTree>
+
+  <BehaviorTree ID="MySub">
+    <PrintVector value="{v4}"/>
+  </BehaviorTree>
+ </root>
+ `
+
+func main() {
+	factory := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(factory)
+
+	pluginPath := "t13_plugin_action.so"
+	// if you don't want to use the hardcoded path, pass it as an argument
+	if len(os.Args) == 2 {
+		pluginPath = os.Args[1]
+	}
+
+	// load the plugin. This will register the action "PrintVector"
+	err := factory.RegisterFromPlugin(pluginPath)
+	if err != nil {
+		panic(err)
+	}
+
+	// print the registered model of PrintVector
+	fmt.Println(core.WriteTreeNodesModelXML(factory, false))
+
+	tree, err := factory.CreateTreeFromText(xml_text)
+	if err != nil {
+		panic(err)
+	}
+	tree.TickWhileRunning()
+}
@@ -0,0 +1,66 @@
+package loggers
+
+import (
+	"github.com/gorustyt/go-behavior/core"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StatusChangeLogger subscribes a callback to the status changes of every node of a tree.
+// It is the common base of all the loggers.
+type StatusChangeLogger struct {
+	enabled              atomic.Bool
+	showTransitionToIdle atomic.Bool
+	mutex                sync.Mutex
+	subscribers          []*core.Subscriber
+	callback             core.StatusChangeCallback
+}
+
+func NewStatusChangeLogger(tree *core.Tree, callback core.StatusChangeCallback) *StatusChangeLogger {
+	l := &StatusChangeLogger{callback: callback}
+	l.enabled.Store(true)
+	l.showTransitionToIdle.Store(true)
+	tree.ApplyVisitor(func(node core.ITreeNode) {
+		l.subscribers = append(l.subscribers, node.SubscribeToStatusChange(l.onStatusChange))
+	})
+	return l
+}
+
+func (l *StatusChangeLogger) onStatusChange(timestamp time.Time, node *core.TreeNode, prev, status core.NodeStatus) {
+	if !l.enabled.Load() {
+		return
+	}
+	if status == core.NodeStatus_IDLE && !l.showTransitionToIdle.Load() {
+		return
+	}
+	l.callback(timestamp, node, prev, status)
+}
+
+func (l *StatusChangeLogger) SetEnabled(enabled bool) {
+	l.enabled.Store(enabled)
+}
+
+func (l *StatusChangeLogger) Enabled() bool {
+	return l.enabled.Load()
+}
+
+// EnableTransitionToIdle decides if the transitions to IDLE are passed to the callback. Default is true.
+func (l *StatusChangeLogger) EnableTransitionToIdle(enable bool) {
+	l.showTransitionToIdle.Store(enable)
+}
+
+func (l *StatusChangeLogger) ShowsTransitionToIdle() bool {
+	return l.showTransitionToIdle.Load()
+}
+
+// Detach unsubscribes the logger from all the nodes of the tree.
+// After that, the logger doesn't receive any notification and can't be attached again.
+func (l *StatusChangeLogger) Detach() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, s := range l.subscribers {
+		s.Unsubscribe()
+	}
+	l.subscribers = nil
+}
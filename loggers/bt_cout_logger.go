@@ -0,0 +1,42 @@
+package loggers
+
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const coutLoggerPathWidth = 25
+
+// StdCoutLogger prints the status transitions of the nodes of a tree, using colors.
+//
+//	[1700000000.123]: root_sequence/battery_ok     IDLE -> SUCCESS
+type StdCoutLogger struct {
+	*StatusChangeLogger
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func NewStdCoutLogger(tree *core.Tree) *StdCoutLogger {
+	l := &StdCoutLogger{writer: os.Stdout}
+	l.StatusChangeLogger = NewStatusChangeLogger(tree, l.callback)
+	return l
+}
+
+// SetWriter redirects the output of the logger. By default, it is os.Stdout
+func (l *StdCoutLogger) SetWriter(w io.Writer) {
+	l.mutex.Lock()
+	l.writer = w
+	l.mutex.Unlock()
+}
+
+func (l *StdCoutLogger) callback(timestamp time.Time, node *core.TreeNode, prev, status core.NodeStatus) {
+	sinceEpoch := float64(timestamp.UnixNano()) / float64(time.Second)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	fmt.Fprintf(l.writer, "[%.3f]: %-*s %s -> %s\n", sinceEpoch, coutLoggerPathWidth, node.FullPath(),
+		prev.StringColor(true), status.StringColor(true))
+}
@@ -0,0 +1,317 @@
+package loggers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+The .btlog format, as read by Groot2:
+
+	"BTCPP4-FileLogger2"    header, 18 bytes
+	protocol                1 byte, currently 1
+	xml size                4 bytes, little endian
+	xml                     the tree, as written by core.WriteTreeToXML with the metadata (_uid and _fullpath)
+	first timestamp         8 bytes, microseconds since epoch
+
+followed by one record of 9 bytes for each transition:
+
+	timestamp               6 bytes, microseconds since the first timestamp
+	uid                     2 bytes
+	status                  1 byte
+
+All the integers are little endian. The previous status is not stored: since every node
+starts IDLE, it is recovered from the previous transition of the same node.
+*/
+
+const (
+	fileLogger2Header     = "BTCPP4-FileLogger2"
+	fileLogger2Protocol   = 1
+	fileLogger2RecordSize = 9
+)
+
+// FileLogger2Transition is a single status change stored in a .btlog file
+type FileLogger2Transition struct {
+	// time elapsed since the first timestamp of the log, with microseconds resolution
+	Timestamp time.Duration
+	UID       uint16
+	Prev      core.NodeStatus
+	Status    core.NodeStatus
+}
+
+// FileLogger2 saves the transitions of a tree in a .btlog file, that can be replayed in Groot2.
+// The file is written by a separate goroutine; call Close to flush it.
+type FileLogger2 struct {
+	*StatusChangeLogger
+	file           *os.File
+	firstTimestamp time.Time
+
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	queue  []FileLogger2Transition
+	closed bool
+	done   chan struct{}
+	err    error
+}
+
+func NewFileLogger2(tree *core.Tree, filepath string) (*FileLogger2, error) {
+	file, err := os.Create(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("problem opening file in FileLogger2: %v", err)
+	}
+	l := &FileLogger2{file: file, done: make(chan struct{})}
+	l.cond = sync.NewCond(&l.mutex)
+
+	l.firstTimestamp = time.Now()
+	err = writeFileLogger2Header(file, core.WriteTreeToXML(tree, true, true), l.firstTimestamp)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("problem writing the header of %v: %v", filepath, err)
+	}
+	l.StatusChangeLogger = NewStatusChangeLogger(tree, l.callback)
+	go l.writerLoop()
+	return l, nil
+}
+
+func writeFileLogger2Header(w io.Writer, treeXML string, firstTimestamp time.Time) error {
+	buf := bytes.NewBufferString(fileLogger2Header)
+	buf.WriteByte(fileLogger2Protocol)
+	binary.Write(buf, binary.LittleEndian, int32(len(treeXML)))
+	buf.WriteString(treeXML)
+	binary.Write(buf, binary.LittleEndian, firstTimestamp.UnixMicro())
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (l *FileLogger2) callback(timestamp time.Time, node *core.TreeNode, prev, status core.NodeStatus) {
+	elapsed := timestamp.Sub(l.firstTimestamp)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.closed {
+		return
+	}
+	l.queue = append(l.queue, FileLogger2Transition{
+		Timestamp: elapsed.Truncate(time.Microsecond),
+		UID:       node.UID(),
+		Prev:      prev,
+		Status:    status,
+	})
+	l.cond.Signal()
+}
+
+func (l *FileLogger2) writerLoop() {
+	defer close(l.done)
+	w := bufio.NewWriter(l.file)
+	var record []byte
+	for {
+		l.mutex.Lock()
+		for len(l.queue) == 0 && !l.closed {
+			l.cond.Wait()
+		}
+		transitions := l.queue
+		l.queue = nil
+		closed := l.closed
+		l.mutex.Unlock()
+
+		for _, trans := range transitions {
+			record = appendTransition(record[:0], trans)
+			w.Write(record)
+		}
+		err := w.Flush()
+		if err != nil {
+			l.mutex.Lock()
+			if l.err == nil {
+				l.err = err
+			}
+			l.mutex.Unlock()
+		}
+		if closed {
+			return
+		}
+	}
+}
+
+// appendTransition serializes the transition in 9 bytes: timestamp (6), uid (2) and status (1)
+func appendTransition(buf []byte, trans FileLogger2Transition) []byte {
+	size := len(buf)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(trans.Timestamp/time.Microsecond))[:size+6]
+	buf = binary.LittleEndian.AppendUint16(buf, trans.UID)
+	return append(buf, byte(trans.Status))
+}
+
+// Close detaches the logger from the tree, writes the pending transitions and closes the file.
+func (l *FileLogger2) Close() error {
+	l.Detach()
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.cond.Signal()
+	l.mutex.Unlock()
+
+	<-l.done
+	err := l.file.Close()
+	if l.err != nil {
+		return l.err
+	}
+	return err
+}
+
+// FileLogger2Reader parses a .btlog file written by FileLogger2 (or by BehaviorTree.CPP).
+type FileLogger2Reader struct {
+	r              *bufio.Reader
+	treeXML        string
+	firstTimestamp time.Time
+	uidToPath      map[uint16]string
+	statuses       map[uint16]core.NodeStatus
+}
+
+// NewFileLogger2Reader reads the header of the log; the transitions are then returned by Next.
+func NewFileLogger2Reader(r io.Reader) (*FileLogger2Reader, error) {
+	reader := &FileLogger2Reader{
+		r:         bufio.NewReader(r),
+		uidToPath: map[uint16]string{},
+		statuses:  map[uint16]core.NodeStatus{},
+	}
+	header := make([]byte, len(fileLogger2Header)+1)
+	_, err := io.ReadFull(reader.r, header)
+	if err != nil {
+		return nil, fmt.Errorf("FileLogger2Reader: can't read the header: %v", err)
+	}
+	if string(header[:len(fileLogger2Header)]) != fileLogger2Header {
+		return nil, fmt.Errorf("FileLogger2Reader: not a .btlog file")
+	}
+	if header[len(fileLogger2Header)] != fileLogger2Protocol {
+		return nil, fmt.Errorf("FileLogger2Reader: unsupported protocol version [%v]", header[len(fileLogger2Header)])
+	}
+
+	var xmlSize int32
+	err = binary.Read(reader.r, binary.LittleEndian, &xmlSize)
+	if err != nil {
+		return nil, fmt.Errorf("FileLogger2Reader: can't read the size of the XML: %v", err)
+	}
+	if xmlSize < 0 {
+		return nil, fmt.Errorf("FileLogger2Reader: invalid size of the XML [%v]", xmlSize)
+	}
+	treeXML := make([]byte, xmlSize)
+	_, err = io.ReadFull(reader.r, treeXML)
+	if err != nil {
+		return nil, fmt.Errorf("FileLogger2Reader: can't read the XML: %v", err)
+	}
+	reader.treeXML = string(treeXML)
+
+	var firstTimestamp int64
+	err = binary.Read(reader.r, binary.LittleEndian, &firstTimestamp)
+	if err != nil {
+		return nil, fmt.Errorf("FileLogger2Reader: can't read the first timestamp: %v", err)
+	}
+	reader.firstTimestamp = time.UnixMicro(firstTimestamp)
+
+	err = reader.parseUIDs()
+	if err != nil {
+		return nil, fmt.Errorf("FileLogger2Reader: invalid XML: %v", err)
+	}
+	return reader, nil
+}
+
+func (r *FileLogger2Reader) parseUIDs() error {
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(r.treeXML)))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var uid, path string
+		for _, attr := range element.Attr {
+			switch attr.Name.Local {
+			case "_uid":
+				uid = attr.Value
+			case "_fullpath":
+				path = attr.Value
+			}
+		}
+		if uid == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(uid, 10, 16)
+		if err != nil {
+			return fmt.Errorf("invalid _uid [%v] in <%v>", uid, element.Name.Local)
+		}
+		r.uidToPath[uint16(value)] = path
+	}
+}
+
+// TreeXML returns the XML of the tree stored in the header
+func (r *FileLogger2Reader) TreeXML() string {
+	return r.treeXML
+}
+
+func (r *FileLogger2Reader) FirstTimestamp() time.Time {
+	return r.firstTimestamp
+}
+
+// UIDToPath returns the map from the UID of the nodes to their full path, as stored in the XML
+func (r *FileLogger2Reader) UIDToPath() map[uint16]string {
+	res := make(map[uint16]string, len(r.uidToPath))
+	for k, v := range r.uidToPath {
+		res[k] = v
+	}
+	return res
+}
+
+// Next returns the next transition of the log, or io.EOF when there are no more.
+func (r *FileLogger2Reader) Next() (trans FileLogger2Transition, err error) {
+	var record [fileLogger2RecordSize]byte
+	_, err = io.ReadFull(r.r, record[:])
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return trans, fmt.Errorf("FileLogger2Reader: truncated transition")
+	}
+	if err != nil {
+		return trans, err
+	}
+	var timestamp [8]byte
+	copy(timestamp[:6], record[:6])
+	trans.Timestamp = time.Duration(binary.LittleEndian.Uint64(timestamp[:])) * time.Microsecond
+	trans.UID = binary.LittleEndian.Uint16(record[6:8])
+	trans.Status = core.NodeStatus(record[8])
+	trans.Prev = r.statuses[trans.UID] // IDLE, if this is the first transition of the node
+	r.statuses[trans.UID] = trans.Status
+	return trans, nil
+}
+
+// ReadAll returns all the remaining transitions of the log
+func (r *FileLogger2Reader) ReadAll() ([]FileLogger2Transition, error) {
+	var res []FileLogger2Transition
+	for {
+		trans, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		res = append(res, trans)
+	}
+}
@@ -0,0 +1,121 @@
+package loggers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileLogger2ReadBack(t *testing.T) {
+	f := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(f)
+	tree, err := f.CreateTreeFromText(`
+<root BTCPP_format="4">
+    <BehaviorTree ID="Main">
+        <Sequence name="root">
+            <AlwaysSuccess name="first"/>
+            <Fallback name="fallback">
+                <AlwaysFailure name="failing"/>
+                <AlwaysSuccess name="succeeding"/>
+            </Fallback>
+        </Sequence>
+    </BehaviorTree>
+</root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "tree.btlog")
+	start := time.Now().Truncate(time.Microsecond)
+	logger, err := NewFileLogger2(tree, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same transitions, as notified to the loggers
+	var want []FileLogger2Transition
+	expected := NewStatusChangeLogger(tree, func(timestamp time.Time, node *core.TreeNode, prev, status core.NodeStatus) {
+		want = append(want, FileLogger2Transition{UID: node.UID(), Prev: prev, Status: status})
+	})
+	for i := 0; i < 2; i++ {
+		if status := tree.TickWhileRunning(); status != core.NodeStatus_SUCCESS {
+			t.Fatalf("status %v, want SUCCESS", status)
+		}
+	}
+	expected.Detach()
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := raw
+	// header: the tag, the protocol, the size of the XML and the XML itself
+	treeXML := core.WriteTreeToXML(tree, true, true)
+	headerSize := len(fileLogger2Header) + 1 + 4 + len(treeXML) + 8
+	if len(data) < headerSize || string(data[:len(fileLogger2Header)]) != fileLogger2Header ||
+		data[len(fileLogger2Header)] != fileLogger2Protocol {
+		t.Fatalf("invalid header % x", data[:min(len(data), len(fileLogger2Header)+1)])
+	}
+	data = data[len(fileLogger2Header)+1:]
+	if size := binary.LittleEndian.Uint32(data); int(size) != len(treeXML) || string(data[4:4+size]) != treeXML {
+		t.Fatalf("XML of size %v, want %v:\n%v", size, len(treeXML), string(data[4:]))
+	}
+	records := data[4+len(treeXML)+8:]
+	if len(want) == 0 || len(records) != len(want)*fileLogger2RecordSize {
+		t.Fatalf("%d bytes of transitions, want %d records of %d bytes", len(records), len(want), fileLogger2RecordSize)
+	}
+
+	reader, err := NewFileLogger2Reader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reader.TreeXML() != treeXML {
+		t.Fatalf("XML read back:\n%v\nwant:\n%v", reader.TreeXML(), treeXML)
+	}
+	if first := reader.FirstTimestamp(); first.Before(start) || first.After(time.Now()) {
+		t.Fatalf("first timestamp %v, not after %v", first, start)
+	}
+	uidToPath := reader.UIDToPath()
+	for _, node := range tree.Subtrees[0].Nodes {
+		if uidToPath[node.UID()] != node.FullPath() {
+			t.Errorf("uid %v: path %v, want %v", node.UID(), uidToPath[node.UID()], node.FullPath())
+		}
+	}
+
+	got, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last time.Duration
+	for i := range got {
+		if got[i].Timestamp < last {
+			t.Fatalf("transition %d: timestamp %v before %v", i, got[i].Timestamp, last)
+		}
+		last = got[i].Timestamp
+		// the record is written as read
+		record := appendTransition(nil, got[i])
+		if !bytes.Equal(record, records[i*fileLogger2RecordSize:(i+1)*fileLogger2RecordSize]) {
+			t.Fatalf("transition %d: record % x, want % x", i, record, records[i*fileLogger2RecordSize:(i+1)*fileLogger2RecordSize])
+		}
+		got[i].Timestamp = 0
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("transitions read back:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestAppendTransition(t *testing.T) {
+	trans := FileLogger2Transition{Timestamp: 0x0102030405*time.Microsecond + 999, UID: 0x0a0b, Status: core.NodeStatus_FAILURE}
+	record := appendTransition([]byte{0xff}, trans)
+	want := []byte{0xff, 0x05, 0x04, 0x03, 0x02, 0x01, 0x00, 0x0b, 0x0a, byte(core.NodeStatus_FAILURE)}
+	if !bytes.Equal(record, want) {
+		t.Fatalf("record % x, want % x", record, want)
+	}
+}
@@ -0,0 +1,142 @@
+package loggers
+
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"sync"
+	"time"
+)
+
+// NodeStatistics counts the transitions of a single node
+type NodeStatistics struct {
+	// Last valid result, either SUCCESS, FAILURE or SKIPPED
+	LastResult core.NodeStatus
+	// Last status. Can be any status, including IDLE or RUNNING
+	CurrentStatus core.NodeStatus
+	// count status transitions, excluding transition to IDLE
+	TransitionsCount int
+	// count number of transitions to SUCCESS
+	SuccessCount int
+	// count number of transitions to FAILURE
+	FailureCount int
+	// count number of transitions to SKIPPED
+	SkipCount int
+
+	LastTimestamp time.Time
+}
+
+// TreeObserver collects the statistics of every node of a tree.
+// It is mostly useful in unit tests, to check that a certain set of transitions happened.
+type TreeObserver struct {
+	*StatusChangeLogger
+	mutex      sync.Mutex
+	statistics map[uint16]*NodeStatistics
+	pathToUID  map[string]uint16
+	uidToPath  map[uint16]string
+}
+
+func NewTreeObserver(tree *core.Tree) *TreeObserver {
+	o := &TreeObserver{
+		statistics: map[uint16]*NodeStatistics{},
+		pathToUID:  map[string]uint16{},
+		uidToPath:  map[uint16]string{},
+	}
+	tree.ApplyVisitor(func(node core.ITreeNode) {
+		uid := node.UID()
+		if _, ok := o.uidToPath[uid]; ok {
+			panic(fmt.Sprintf("TreeObserver: the UID [%v] of node [%v] is not unique", uid, node.FullPath()))
+		}
+		o.pathToUID[node.FullPath()] = uid
+		o.uidToPath[uid] = node.FullPath()
+		o.statistics[uid] = &NodeStatistics{}
+	})
+	o.StatusChangeLogger = NewStatusChangeLogger(tree, o.callback)
+	return o
+}
+
+func (o *TreeObserver) callback(timestamp time.Time, node *core.TreeNode, prev, status core.NodeStatus) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	stats, ok := o.statistics[node.UID()]
+	if !ok {
+		return
+	}
+	stats.CurrentStatus = status
+	stats.LastTimestamp = timestamp
+
+	if status == core.NodeStatus_IDLE {
+		return
+	}
+	stats.TransitionsCount++
+	switch status {
+	case core.NodeStatus_SUCCESS:
+		stats.SuccessCount++
+		stats.LastResult = status
+	case core.NodeStatus_FAILURE:
+		stats.FailureCount++
+		stats.LastResult = status
+	case core.NodeStatus_SKIPPED:
+		stats.SkipCount++
+		stats.LastResult = status
+	}
+}
+
+// ResetStatistics sets all the counters back to zero
+func (o *TreeObserver) ResetStatistics() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for uid := range o.statistics {
+		o.statistics[uid] = &NodeStatistics{}
+	}
+}
+
+// GetStatistics returns a copy of the statistics of the node with the given UID
+func (o *TreeObserver) GetStatistics(uid uint16) (res NodeStatistics, err error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	stats, ok := o.statistics[uid]
+	if !ok {
+		return res, fmt.Errorf("TreeObserver: no node with UID [%v]", uid)
+	}
+	return *stats, nil
+}
+
+// GetStatisticsByPath returns a copy of the statistics of the node with the given FullPath()
+func (o *TreeObserver) GetStatisticsByPath(path string) (res NodeStatistics, err error) {
+	o.mutex.Lock()
+	uid, ok := o.pathToUID[path]
+	o.mutex.Unlock()
+	if !ok {
+		return res, fmt.Errorf("TreeObserver: no node with path [%v]", path)
+	}
+	return o.GetStatistics(uid)
+}
+
+// Statistics returns a copy of the statistics of all the nodes, by UID
+func (o *TreeObserver) Statistics() map[uint16]NodeStatistics {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	res := make(map[uint16]NodeStatistics, len(o.statistics))
+	for uid, stats := range o.statistics {
+		res[uid] = *stats
+	}
+	return res
+}
+
+// PathToUID returns the map from the FullPath() of the nodes to their UID
+func (o *TreeObserver) PathToUID() map[string]uint16 {
+	res := make(map[string]uint16, len(o.pathToUID))
+	for k, v := range o.pathToUID {
+		res[k] = v
+	}
+	return res
+}
+
+// UIDToPath returns the map from the UID of the nodes to their FullPath()
+func (o *TreeObserver) UIDToPath() map[uint16]string {
+	res := make(map[uint16]string, len(o.uidToPath))
+	for k, v := range o.uidToPath {
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,97 @@
+package loggers
+
+import (
+	"github.com/gorustyt/go-behavior/builtins"
+	"github.com/gorustyt/go-behavior/core"
+	"testing"
+)
+
+func TestTreeObserver(t *testing.T) {
+	f := core.NewBehaviorTreeFactory()
+	builtins.RegisterNodes(f)
+	tree, err := f.CreateTreeFromText(`
+<root BTCPP_format="4">
+    <BehaviorTree ID="Main">
+        <Sequence name="root">
+            <Fallback name="fallback">
+                <AlwaysFailure name="failing"/>
+                <AlwaysSuccess name="succeeding"/>
+            </Fallback>
+            <AlwaysSuccess name="skipped_by_condition" _skipIf="true"/>
+            <SkipUnlessUpdated name="not_updated" entry="{missing}">
+                <AlwaysSuccess name="never_ticked"/>
+            </SkipUnlessUpdated>
+            <AlwaysSuccess name="last"/>
+        </Sequence>
+    </BehaviorTree>
+</root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := NewTreeObserver(tree)
+
+	type counts struct {
+		transitions, success, failure, skip int
+		lastResult                          core.NodeStatus
+	}
+	tests := []struct {
+		path string
+		want counts
+	}{
+		// IDLE -> RUNNING -> SUCCESS; the transitions to IDLE aren't counted
+		{"root", counts{2, 1, 0, 0, core.NodeStatus_SUCCESS}},
+		{"fallback", counts{2, 1, 0, 0, core.NodeStatus_SUCCESS}},
+		{"failing", counts{1, 0, 1, 0, core.NodeStatus_FAILURE}},
+		{"succeeding", counts{1, 1, 0, 0, core.NodeStatus_SUCCESS}},
+		// SKIPPED by the precondition, notified by ExecuteTick without ticking the node
+		{"skipped_by_condition", counts{1, 0, 0, 1, core.NodeStatus_SKIPPED}},
+		// SKIPPED returned by Tick
+		{"not_updated", counts{1, 0, 0, 1, core.NodeStatus_SKIPPED}},
+		{"never_ticked", counts{0, 0, 0, 0, core.NodeStatus_IDLE}},
+		{"last", counts{1, 1, 0, 0, core.NodeStatus_SUCCESS}},
+	}
+	check := func(ticks int) {
+		t.Helper()
+		for _, tt := range tests {
+			stats, err := observer.GetStatisticsByPath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := counts{stats.TransitionsCount, stats.SuccessCount, stats.FailureCount, stats.SkipCount, stats.LastResult}
+			want := counts{tt.want.transitions * ticks, tt.want.success * ticks, tt.want.failure * ticks, tt.want.skip * ticks, tt.want.lastResult}
+			if ticks == 0 {
+				want.lastResult = core.NodeStatus_IDLE
+			}
+			if got != want {
+				t.Errorf("after %d ticks [%v]: %+v, want %+v", ticks, tt.path, got, want)
+			}
+			// the tree is halted after every tick, but the skipped nodes aren't notified
+			// of a transition back to IDLE, because their status doesn't change
+			wantCurrent := core.NodeStatus_IDLE
+			if ticks > 0 && tt.want.lastResult == core.NodeStatus_SKIPPED {
+				wantCurrent = core.NodeStatus_SKIPPED
+			}
+			if stats.CurrentStatus != wantCurrent {
+				t.Errorf("after %d ticks [%v]: current status %v, want %v", ticks, tt.path, stats.CurrentStatus, wantCurrent)
+			}
+		}
+	}
+
+	for ticks := 1; ticks <= 2; ticks++ {
+		if status := tree.TickWhileRunning(); status != core.NodeStatus_SUCCESS {
+			t.Fatalf("status %v, want SUCCESS", status)
+		}
+		check(ticks)
+	}
+
+	observer.ResetStatistics()
+	check(0)
+
+	if _, err := observer.GetStatisticsByPath("unknown"); err == nil {
+		t.Error("no error for an unknown path")
+	}
+	uid := observer.PathToUID()["last"]
+	if observer.UIDToPath()[uid] != "last" {
+		t.Errorf("UIDToPath()[%v] = %v, want last", uid, observer.UIDToPath()[uid])
+	}
+}
@@ -0,0 +1,195 @@
+package loggers
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/loggers/zmtp"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Groot2Client speaks the protocol of Groot2 with a Groot2Publisher.
+// It is useful to test the publisher, or to build custom monitoring tools.
+type Groot2Client struct {
+	request    *zmtp.Socket
+	subscriber *zmtp.Socket
+}
+
+// NewGroot2Client connects to the publisher listening on host:port (requests) and host:port+1 (notifications)
+func NewGroot2Client(host string, port int) (*Groot2Client, error) {
+	request, err := zmtp.Dial(zmtp.REQ, net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := zmtp.Dial(zmtp.SUB, net.JoinHostPort(host, strconv.Itoa(port+1)))
+	if err != nil {
+		request.Close()
+		return nil, err
+	}
+	err = subscriber.Subscribe(nil)
+	if err != nil {
+		request.Close()
+		subscriber.Close()
+		return nil, err
+	}
+	return &Groot2Client{request: request, subscriber: subscriber}, nil
+}
+
+func (c *Groot2Client) Close() error {
+	c.request.Close()
+	return c.subscriber.Close()
+}
+
+// Request sends a request with an optional payload and returns the frames of the reply, after the header.
+func (c *Groot2Client) Request(requestType RequestType, payload ...[]byte) ([][]byte, error) {
+	header := NewRequestHeader(requestType)
+	err := c.request.Send(append([][]byte{header.Serialize()}, payload...)...)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.request.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) == 2 && string(reply[0]) == "error" {
+		return nil, fmt.Errorf("Groot2Publisher: %s", reply[1])
+	}
+	if len(reply) == 0 {
+		return nil, fmt.Errorf("Groot2Client: empty reply")
+	}
+	replyHeader, err := DeserializeReplyHeader(reply[0])
+	if err != nil {
+		return nil, err
+	}
+	if replyHeader.Request.UniqueId != header.UniqueId || replyHeader.Request.Type != requestType {
+		return nil, fmt.Errorf("Groot2Client: the reply doesn't match the request")
+	}
+	return reply[1:], nil
+}
+
+func (c *Groot2Client) requestOne(requestType RequestType, payload ...[]byte) ([]byte, error) {
+	reply, err := c.Request(requestType, payload...)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) != 1 {
+		return nil, fmt.Errorf("Groot2Client: expected 1 frame in the reply, received %v", len(reply))
+	}
+	return reply[0], nil
+}
+
+// FullTree returns the XML of the tree, with the _uid and _fullpath attributes
+func (c *Groot2Client) FullTree() (string, error) {
+	data, err := c.requestOne(RequestType_FULLTREE)
+	return string(data), err
+}
+
+// NodeStatusDump is the status of a node, as sent to Groot2.
+// When the node is IDLE, Prev is the status it had before being reset.
+type NodeStatusDump struct {
+	Status core.NodeStatus
+	Prev   core.NodeStatus
+}
+
+// Status returns the current status of all the nodes, by UID
+func (c *Groot2Client) Status() (map[uint16]NodeStatusDump, error) {
+	data, err := c.requestOne(RequestType_STATUS)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%3 != 0 {
+		return nil, fmt.Errorf("Groot2Client: malformed status dump")
+	}
+	res := map[uint16]NodeStatusDump{}
+	for i := 0; i < len(data); i += 3 {
+		var dump NodeStatusDump
+		value := data[i+2]
+		if value >= groot2StatusIdleFromPrevOffset {
+			dump.Status = core.NodeStatus_IDLE
+			dump.Prev = core.NodeStatus(value - groot2StatusIdleFromPrevOffset)
+		} else {
+			dump.Status = core.NodeStatus(value)
+		}
+		res[binary.LittleEndian.Uint16(data[i:])] = dump
+	}
+	return res, nil
+}
+
+// Blackboards returns the content of the blackboards of the subtrees, by subtree instance name
+func (c *Groot2Client) Blackboards(subtrees ...string) (map[string]any, error) {
+	data, err := c.requestOne(RequestType_BLACKBOARD, []byte(strings.Join(subtrees, ";")))
+	if err != nil {
+		return nil, err
+	}
+	value, err := msgpackUnmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("Groot2Client: malformed blackboards dump")
+	}
+	return res, nil
+}
+
+func (c *Groot2Client) InsertHooks(hooks ...*Hook) error {
+	data, err := json.Marshal(hooks)
+	if err != nil {
+		return err
+	}
+	_, err = c.Request(RequestType_HOOK_INSERT, data)
+	return err
+}
+
+func (c *Groot2Client) RemoveHook(position HookPosition, uid uint16) error {
+	data, _ := json.Marshal(map[string]any{"uid": uid, "position": position})
+	_, err := c.Request(RequestType_HOOK_REMOVE, data)
+	return err
+}
+
+func (c *Groot2Client) UnlockBreakpoint(position HookPosition, uid uint16, result core.NodeStatus, remove bool) error {
+	data, _ := json.Marshal(map[string]any{
+		"uid":              uid,
+		"position":         position,
+		"desired_status":   result.String(),
+		"remove_when_done": remove,
+	})
+	_, err := c.Request(RequestType_BREAKPOINT_UNLOCK, data)
+	return err
+}
+
+func (c *Groot2Client) Hooks() ([]*Hook, error) {
+	data, err := c.requestOne(RequestType_HOOKS_DUMP)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []*Hook
+	err = json.Unmarshal(data, &hooks)
+	return hooks, err
+}
+
+// WaitBreakpoint waits for the notification that a node reached a breakpoint, and returns its UID
+func (c *Groot2Client) WaitBreakpoint(timeout time.Duration) (uint16, error) {
+	for {
+		msg, err := c.subscriber.RecvTimeout(timeout)
+		if err != nil {
+			return 0, err
+		}
+		if len(msg) != 2 {
+			continue
+		}
+		header, err := DeserializeRequestHeader(msg[0])
+		if err != nil || header.Type != RequestType_BREAKPOINT_REACHED {
+			continue
+		}
+		uid, err := strconv.ParseUint(string(msg[1]), 10, 16)
+		if err != nil {
+			return 0, fmt.Errorf("Groot2Client: invalid UID [%s]", msg[1])
+		}
+		return uint16(uid), nil
+	}
+}
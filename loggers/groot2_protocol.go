@@ -0,0 +1,171 @@
+package loggers
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"sync"
+)
+
+// RequestType is the first byte of the requests that Groot2 sends to the Groot2Publisher
+type RequestType uint8
+
+const (
+	RequestType_UNDEFINED RequestType = 0
+	// Request the entire tree definition as XML
+	RequestType_FULLTREE RequestType = 'T'
+	// Request the status of all the nodes
+	RequestType_STATUS RequestType = 'S'
+	// request the blackboard values
+	RequestType_BLACKBOARD RequestType = 'B'
+	// Groot requests the insertion of a hook
+	RequestType_HOOK_INSERT RequestType = 'I'
+	// Groot requests to remove a hook
+	RequestType_HOOK_REMOVE RequestType = 'R'
+	// Notify Groot that we reached a breakpoint
+	RequestType_BREAKPOINT_REACHED RequestType = 'N'
+	// Groot will unlock a breakpoint
+	RequestType_BREAKPOINT_UNLOCK RequestType = 'U'
+	// Groot requests to dump the hooks
+	RequestType_HOOKS_DUMP        RequestType = 'D'
+	RequestType_REMOVE_ALL_HOOKS  RequestType = 'A'
+	RequestType_DISABLE_ALL_HOOKS RequestType = 'X'
+	RequestType_TOGGLE_RECORDING  RequestType = 'r'
+	RequestType_GET_TRANSITIONS   RequestType = 't'
+)
+
+const (
+	groot2ProtocolID        = 2
+	groot2RequestHeaderSize = 6
+	groot2ReplyHeaderSize   = groot2RequestHeaderSize + 16
+)
+
+// RequestHeader is serialized as protocol (1 byte), type (1 byte), uniqueId (4 bytes, little endian)
+type RequestHeader struct {
+	UniqueId uint32
+	Protocol uint8
+	Type     RequestType
+}
+
+func NewRequestHeader(requestType RequestType) RequestHeader {
+	var id [4]byte
+	rand.Read(id[:])
+	return RequestHeader{UniqueId: binary.LittleEndian.Uint32(id[:]), Protocol: groot2ProtocolID, Type: requestType}
+}
+
+func (h RequestHeader) Serialize() []byte {
+	buf := []byte{h.Protocol, byte(h.Type)}
+	return binary.LittleEndian.AppendUint32(buf, h.UniqueId)
+}
+
+func DeserializeRequestHeader(buf []byte) (h RequestHeader, err error) {
+	if len(buf) != groot2RequestHeaderSize {
+		return h, fmt.Errorf("wrong request header")
+	}
+	h.Protocol = buf[0]
+	h.Type = RequestType(buf[1])
+	h.UniqueId = binary.LittleEndian.Uint32(buf[2:])
+	return h, nil
+}
+
+// ReplyHeader is the RequestHeader of the request, followed by the unique ID of the tree (16 bytes)
+type ReplyHeader struct {
+	Request RequestHeader
+	TreeId  [16]byte
+}
+
+func (h ReplyHeader) Serialize() []byte {
+	return append(h.Request.Serialize(), h.TreeId[:]...)
+}
+
+func DeserializeReplyHeader(buf []byte) (h ReplyHeader, err error) {
+	if len(buf) != groot2ReplyHeaderSize {
+		return h, fmt.Errorf("wrong reply header")
+	}
+	h.Request, err = DeserializeRequestHeader(buf[:groot2RequestHeaderSize])
+	copy(h.TreeId[:], buf[groot2RequestHeaderSize:])
+	return h, err
+}
+
+type HookPosition int
+
+const (
+	HookPosition_PRE HookPosition = iota
+	HookPosition_POST
+)
+
+type HookMode int
+
+const (
+	// interactive breakpoints are unblocked using UnlockBreakpoint()
+	HookMode_BREAKPOINT HookMode = iota
+	// the tick of the node is replaced by the desired status
+	HookMode_REPLACE
+)
+
+// Hook is a breakpoint, or a replacement of the result of a node, inserted by Groot2
+type Hook struct {
+	// used to enable/disable the breakpoint
+	Enabled  bool
+	Position HookPosition
+	NodeUID  uint16
+	Mode     HookMode
+	// once finished self-destroy
+	RemoveWhenDone bool
+	// result to be returned
+	DesiredStatus core.NodeStatus
+
+	mutex  sync.Mutex
+	wakeup *sync.Cond
+	// set to true to unlock an interactive breakpoint
+	ready bool
+}
+
+func NewHook() *Hook {
+	h := &Hook{Enabled: true, DesiredStatus: core.NodeStatus_SKIPPED}
+	h.wakeup = sync.NewCond(&h.mutex)
+	return h
+}
+
+type hookJSON struct {
+	Enabled       bool   `json:"enabled"`
+	UID           uint16 `json:"uid"`
+	Mode          int    `json:"mode"`
+	Once          bool   `json:"once"`
+	DesiredStatus string `json:"desired_status"`
+	Position      int    `json:"position"`
+}
+
+func (h *Hook) MarshalJSON() ([]byte, error) {
+	status := h.DesiredStatus
+	return json.Marshal(hookJSON{
+		Enabled:       h.Enabled,
+		UID:           h.NodeUID,
+		Mode:          int(h.Mode),
+		Once:          h.RemoveWhenDone,
+		DesiredStatus: status.String(),
+		Position:      int(h.Position),
+	})
+}
+
+func (h *Hook) UnmarshalJSON(data []byte) error {
+	var v hookJSON
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+	var status core.NodeStatus
+	err = status.FromString(v.DesiredStatus)
+	if err != nil {
+		return err
+	}
+	h.Enabled = v.Enabled
+	h.NodeUID = v.UID
+	h.Mode = HookMode(v.Mode)
+	h.RemoveWhenDone = v.Once
+	h.DesiredStatus = status
+	h.Position = HookPosition(v.Position)
+	return nil
+}
@@ -0,0 +1,596 @@
+package loggers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"github.com/gorustyt/go-behavior/loggers/zmtp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	groot2DefaultHeartbeatDelay    = 5 * time.Second
+	groot2MaxRecordedTransitions   = 1000
+	groot2HeartbeatCheckPeriod     = 10 * time.Millisecond
+	groot2StatusIdleFromPrevOffset = 10
+)
+
+/*
+Groot2Publisher is used to create an interface between your BT.CPP
+executor and Groot2.
+
+An inter-process communication mechanism allows the two processes
+to communicate through a ZeroMQ socket (the pure Go implementation in the zmtp package).
+Groot2 sends its requests to the REP socket on the given port, and receives
+the notifications of the breakpoints from the PUB socket on port+1.
+
+Groot2 is able to:
+  - retrieve the structure of the tree (XML)
+  - the status of all the nodes
+  - the content of the blackboards of the subtrees
+  - insert breakpoints and replace the result of nodes
+*/
+type Groot2Publisher struct {
+	*StatusChangeLogger
+	tree      *core.Tree
+	treeXML   string
+	treeId    [16]byte
+	server    *zmtp.Socket
+	publisher *zmtp.Socket
+
+	statusMutex        sync.Mutex
+	statusBuffer       []byte
+	statusOffsets      map[uint16]int
+	recording          bool
+	recordingFirstTime time.Time
+	transitions        []FileLogger2Transition
+
+	nodesByUID map[uint16]core.ITreeNode
+	hooksMutex sync.Mutex
+	preHooks   map[uint16]*Hook
+	postHooks  map[uint16]*Hook
+
+	lastHeartbeat     atomic.Int64
+	maxHeartbeatDelay atomic.Int64
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewGroot2Publisher serves the tree on all the interfaces, on ports port and port+1.
+// The default port used by Groot2 is 1667.
+func NewGroot2Publisher(tree *core.Tree, port int) (*Groot2Publisher, error) {
+	p := &Groot2Publisher{
+		tree:          tree,
+		treeXML:       core.WriteTreeToXML(tree, true, true),
+		statusOffsets: map[uint16]int{},
+		nodesByUID:    map[uint16]core.ITreeNode{},
+		preHooks:      map[uint16]*Hook{},
+		postHooks:     map[uint16]*Hook{},
+		done:          make(chan struct{}),
+	}
+	rand.Read(p.treeId[:])
+	p.maxHeartbeatDelay.Store(int64(groot2DefaultHeartbeatDelay))
+	p.lastHeartbeat.Store(time.Now().UnixNano())
+
+	// the status buffer contains, for each node, the UID (2 bytes) and the status (1 byte)
+	tree.ApplyVisitor(func(node core.ITreeNode) {
+		uid := node.UID()
+		p.nodesByUID[uid] = node
+		p.statusOffsets[uid] = len(p.statusBuffer)
+		p.statusBuffer = binary.LittleEndian.AppendUint16(p.statusBuffer, uid)
+		p.statusBuffer = append(p.statusBuffer, byte(node.Status()))
+	})
+
+	var err error
+	p.server, err = zmtp.Listen(zmtp.REP, fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("Groot2Publisher: can't listen on port %v: %v", port, err)
+	}
+	p.publisher, err = zmtp.Listen(zmtp.PUB, fmt.Sprintf(":%d", port+1))
+	if err != nil {
+		p.server.Close()
+		return nil, fmt.Errorf("Groot2Publisher: can't listen on port %v: %v", port+1, err)
+	}
+	p.StatusChangeLogger = NewStatusChangeLogger(tree, p.callback)
+
+	p.wg.Add(2)
+	go p.serverLoop()
+	go p.heartbeatLoop()
+	return p, nil
+}
+
+// SetMaxHeartbeatDelay sets the time after which, if Groot2 doesn't send any request,
+// it is considered disconnected and all the hooks are disabled. Default is 5 seconds.
+func (p *Groot2Publisher) SetMaxHeartbeatDelay(delay time.Duration) {
+	p.maxHeartbeatDelay.Store(int64(delay))
+}
+
+func (p *Groot2Publisher) MaxHeartbeatDelay() time.Duration {
+	return time.Duration(p.maxHeartbeatDelay.Load())
+}
+
+// Close stops the server, removes all the hooks (unlocking the breakpoints) and detaches the logger.
+func (p *Groot2Publisher) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+		p.server.Close()
+		p.publisher.Close()
+		p.wg.Wait()
+		p.RemoveAllHooks()
+		p.Detach()
+	})
+	return nil
+}
+
+func (p *Groot2Publisher) callback(timestamp time.Time, node *core.TreeNode, prev, status core.NodeStatus) {
+	// the transitions to IDLE also say which was the previous status
+	value := byte(status)
+	if status == core.NodeStatus_IDLE {
+		value = groot2StatusIdleFromPrevOffset + byte(prev)
+	}
+	p.statusMutex.Lock()
+	defer p.statusMutex.Unlock()
+	offset, ok := p.statusOffsets[node.UID()]
+	if !ok {
+		return
+	}
+	p.statusBuffer[offset+2] = value
+
+	if p.recording && status != core.NodeStatus_IDLE {
+		elapsed := timestamp.Sub(p.recordingFirstTime)
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		p.transitions = append(p.transitions, FileLogger2Transition{
+			Timestamp: elapsed.Truncate(time.Microsecond),
+			UID:       node.UID(),
+			Prev:      prev,
+			Status:    status,
+		})
+		if len(p.transitions) > groot2MaxRecordedTransitions {
+			p.transitions = p.transitions[len(p.transitions)-groot2MaxRecordedTransitions:]
+		}
+	}
+}
+
+func (p *Groot2Publisher) heartbeatLoop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(groot2HeartbeatCheckPeriod)
+	defer ticker.Stop()
+	hasHeartbeat := true
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+		prevHeartbeat := hasHeartbeat
+		elapsed := time.Since(time.Unix(0, p.lastHeartbeat.Load()))
+		hasHeartbeat = elapsed < p.MaxHeartbeatDelay()
+		// if we loose or gain heartbeat, disable/enable all breakpoints
+		if hasHeartbeat != prevHeartbeat {
+			p.EnableAllHooks(hasHeartbeat)
+		}
+	}
+}
+
+func (p *Groot2Publisher) serverLoop() {
+	defer p.wg.Done()
+	for {
+		request, err := p.server.Recv()
+		if err != nil {
+			return
+		}
+		// this heartbeat will help establishing if Groot is connected or not
+		p.lastHeartbeat.Store(time.Now().UnixNano())
+
+		reply, err := p.handleRequest(request)
+		if err != nil {
+			reply = [][]byte{[]byte("error"), []byte(err.Error())}
+		}
+		if p.server.Send(reply...) != nil {
+			select {
+			case <-p.done:
+				return
+			default:
+			}
+		}
+	}
+}
+
+func (p *Groot2Publisher) handleRequest(request [][]byte) ([][]byte, error) {
+	if len(request) == 0 {
+		return nil, fmt.Errorf("wrong request header")
+	}
+	requestHeader, err := DeserializeRequestHeader(request[0])
+	if err != nil {
+		return nil, err
+	}
+	replyHeader := ReplyHeader{Request: requestHeader, TreeId: p.treeId}
+	replyHeader.Request.Protocol = groot2ProtocolID
+	reply := [][]byte{replyHeader.Serialize()}
+
+	payload := func() (string, error) {
+		if len(request) != 2 {
+			return "", fmt.Errorf("must be 2 parts message")
+		}
+		return string(request[1]), nil
+	}
+
+	switch requestHeader.Type {
+	case RequestType_FULLTREE:
+		reply = append(reply, []byte(p.treeXML))
+	case RequestType_STATUS:
+		p.statusMutex.Lock()
+		reply = append(reply, bytes.Clone(p.statusBuffer))
+		p.statusMutex.Unlock()
+	case RequestType_BLACKBOARD:
+		names, err := payload()
+		if err != nil {
+			return nil, err
+		}
+		dump, err := p.generateBlackboardsDump(names)
+		if err != nil {
+			return nil, err
+		}
+		reply = append(reply, dump)
+	case RequestType_HOOK_INSERT:
+		data, err := payload()
+		if err != nil {
+			return nil, err
+		}
+		// the json may contain a Hook or an array of Hooks
+		var hooks []json.RawMessage
+		if strings.HasPrefix(strings.TrimSpace(data), "[") {
+			err = json.Unmarshal([]byte(data), &hooks)
+		} else {
+			hooks = []json.RawMessage{json.RawMessage(data)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range hooks {
+			err = p.insertHookFromJSON(hook)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case RequestType_BREAKPOINT_UNLOCK:
+		data, err := payload()
+		if err != nil {
+			return nil, err
+		}
+		var unlock struct {
+			UID            uint16       `json:"uid"`
+			DesiredStatus  string       `json:"desired_status"`
+			Position       HookPosition `json:"position"`
+			RemoveWhenDone bool         `json:"remove_when_done"`
+		}
+		err = json.Unmarshal([]byte(data), &unlock)
+		if err != nil {
+			return nil, err
+		}
+		desiredStatus := core.NodeStatus_SKIPPED
+		switch unlock.DesiredStatus {
+		case "SUCCESS":
+			desiredStatus = core.NodeStatus_SUCCESS
+		case "FAILURE":
+			desiredStatus = core.NodeStatus_FAILURE
+		}
+		if !p.UnlockBreakpoint(unlock.Position, unlock.UID, desiredStatus, unlock.RemoveWhenDone) {
+			return nil, fmt.Errorf("Node ID not found")
+		}
+	case RequestType_REMOVE_ALL_HOOKS:
+		p.RemoveAllHooks()
+	case RequestType_DISABLE_ALL_HOOKS:
+		p.EnableAllHooks(false)
+	case RequestType_HOOK_REMOVE:
+		data, err := payload()
+		if err != nil {
+			return nil, err
+		}
+		var remove struct {
+			UID      uint16       `json:"uid"`
+			Position HookPosition `json:"position"`
+		}
+		err = json.Unmarshal([]byte(data), &remove)
+		if err != nil {
+			return nil, err
+		}
+		if !p.RemoveHook(remove.Position, remove.UID) {
+			return nil, fmt.Errorf("Node ID not found")
+		}
+	case RequestType_HOOKS_DUMP:
+		p.hooksMutex.Lock()
+		hooks := make([]*Hook, 0, len(p.preHooks))
+		for _, uid := range sortedUIDs(p.preHooks) {
+			hooks = append(hooks, p.preHooks[uid])
+		}
+		p.hooksMutex.Unlock()
+		data, err := json.Marshal(hooks)
+		if err != nil {
+			return nil, err
+		}
+		reply = append(reply, data)
+	case RequestType_TOGGLE_RECORDING:
+		cmd, err := payload()
+		if err != nil {
+			return nil, err
+		}
+		p.statusMutex.Lock()
+		switch cmd {
+		case "start":
+			p.recording = true
+			// to keep the first time for callback
+			p.recordingFirstTime = time.Now()
+			p.transitions = nil
+			reply = append(reply, []byte(strconv.FormatInt(p.recordingFirstTime.UnixMicro(), 10)))
+		case "stop":
+			p.recording = false
+		}
+		p.statusMutex.Unlock()
+	case RequestType_GET_TRANSITIONS:
+		p.statusMutex.Lock()
+		buf := make([]byte, 0, fileLogger2RecordSize*len(p.transitions))
+		for _, trans := range p.transitions {
+			buf = appendTransition(buf, trans)
+		}
+		p.transitions = nil
+		p.statusMutex.Unlock()
+		reply = append(reply, buf)
+	default:
+		return nil, fmt.Errorf("Request not recognized")
+	}
+	return reply, nil
+}
+
+// generateBlackboardsDump returns, as MessagePack, the content of the blackboards of the
+// subtrees listed in names, separated by ';'
+func (p *Groot2Publisher) generateBlackboardsDump(names string) ([]byte, error) {
+	dump := map[string]any{}
+	for _, name := range strings.Split(names, ";") {
+		for _, subtree := range p.tree.Subtrees {
+			if subtree.InstanceName == name {
+				dump[name] = blackboardToJSON(subtree.Blackboard)
+			}
+		}
+	}
+	return msgpackMarshal(dump)
+}
+
+// blackboardToJSON converts the values of the blackboard to generic JSON values,
+// using the JsonExporter of core. The entries that can't be represented in JSON are skipped.
+func blackboardToJSON(bb *core.Blackboard) map[string]any {
+	res := map[string]any{}
+	if bb == nil {
+		return res
+	}
+	data, err := core.ExportBlackboardToJSON(bb)
+	if err != nil {
+		return res
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.Decode(&res)
+	return res
+}
+
+//----------------------------------------------------------------------
+
+func sortedUIDs(hooks map[uint16]*Hook) []uint16 {
+	res := make([]uint16, 0, len(hooks))
+	for uid := range hooks {
+		res = append(res, uid)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+func (p *Groot2Publisher) hooksOf(position HookPosition) map[uint16]*Hook {
+	if position == HookPosition_POST {
+		return p.postHooks
+	}
+	return p.preHooks
+}
+
+// GetHook returns the hook of the node at the given position, or nil
+func (p *Groot2Publisher) GetHook(position HookPosition, uid uint16) *Hook {
+	p.hooksMutex.Lock()
+	defer p.hooksMutex.Unlock()
+	return p.hooksOf(position)[uid]
+}
+
+func (p *Groot2Publisher) insertHookFromJSON(data []byte) error {
+	var key struct {
+		UID      uint16       `json:"uid"`
+		Position HookPosition `json:"position"`
+	}
+	err := json.Unmarshal(data, &key)
+	if err != nil {
+		return err
+	}
+	hook := p.GetHook(key.Position, key.UID)
+	if hook == nil {
+		// if not found, create a new one
+		hook = NewHook()
+		err = json.Unmarshal(data, hook)
+		if err != nil {
+			return err
+		}
+		p.InsertHook(hook)
+		return nil
+	}
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	wasInteractive := hook.Mode == HookMode_BREAKPOINT
+	err = json.Unmarshal(data, hook)
+	if err != nil {
+		return err
+	}
+	// if it WAS interactive and it is not anymore, unlock it
+	if wasInteractive && hook.Mode == HookMode_REPLACE {
+		hook.ready = true
+		hook.wakeup.Broadcast()
+	}
+	return nil
+}
+
+// InsertHook injects the hook in the node with UID hook.NodeUID.
+// It returns false if there is no such node.
+func (p *Groot2Publisher) InsertHook(hook *Hook) bool {
+	node, ok := p.nodesByUID[hook.NodeUID]
+	if !ok {
+		return false
+	}
+	if hook.wakeup == nil {
+		hook.wakeup = sync.NewCond(&hook.mutex)
+	}
+	p.hooksMutex.Lock()
+	defer p.hooksMutex.Unlock()
+	p.hooksOf(hook.Position)[hook.NodeUID] = hook
+	if hook.Position == HookPosition_POST {
+		node.SetPostTickFunction(func(n *core.TreeNode, status core.NodeStatus) core.NodeStatus {
+			return p.runHook(hook, n)
+		})
+	} else {
+		node.SetPreTickFunction(func(n *core.TreeNode) core.NodeStatus {
+			return p.runHook(hook, n)
+		})
+	}
+	return true
+}
+
+// runHook is executed in the goroutine ticking the tree: a breakpoint blocks it until
+// Groot2 unlocks it, or the hook is disabled.
+func (p *Groot2Publisher) runHook(hook *Hook, node *core.TreeNode) core.NodeStatus {
+	hook.mutex.Lock()
+	if !hook.Enabled {
+		hook.mutex.Unlock()
+		return core.NodeStatus_IDLE
+	}
+	// Notify that a breakpoint was reached, using the publisher
+	header := NewRequestHeader(RequestType_BREAKPOINT_REACHED)
+	p.publisher.Send(header.Serialize(), []byte(strconv.Itoa(int(hook.NodeUID))))
+
+	// wait until someone wake us up
+	if hook.Mode == HookMode_BREAKPOINT {
+		for !hook.ready && hook.Enabled {
+			hook.wakeup.Wait()
+		}
+		hook.ready = false
+		// wait was unblocked but it could be the breakpoint becoming disabled.
+		// If this is the case, skip this
+		if !hook.Enabled {
+			hook.mutex.Unlock()
+			return core.NodeStatus_IDLE
+		}
+	}
+	status := hook.DesiredStatus
+	removeWhenDone := hook.RemoveWhenDone
+	hook.mutex.Unlock()
+
+	// self-destruction
+	if removeWhenDone {
+		p.hooksMutex.Lock()
+		if p.hooksOf(hook.Position)[hook.NodeUID] == hook {
+			delete(p.hooksOf(hook.Position), hook.NodeUID)
+			if hook.Position == HookPosition_POST {
+				node.SetPostTickFunction(nil)
+			} else {
+				node.SetPreTickFunction(nil)
+			}
+		}
+		p.hooksMutex.Unlock()
+	}
+	return status
+}
+
+// UnlockBreakpoint wakes up the node blocked by a breakpoint, that will return result.
+// It returns false if the node has no hook at the given position.
+func (p *Groot2Publisher) UnlockBreakpoint(position HookPosition, uid uint16, result core.NodeStatus, remove bool) bool {
+	hook := p.GetHook(position, uid)
+	if hook == nil {
+		return false
+	}
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	hook.DesiredStatus = result
+	hook.RemoveWhenDone = hook.RemoveWhenDone || remove
+	if hook.Mode == HookMode_BREAKPOINT {
+		hook.ready = true
+		hook.wakeup.Broadcast()
+	}
+	return true
+}
+
+// RemoveHook removes the hook of the node, unlocking it if it is blocked in a breakpoint.
+// It returns false if the node has no hook at the given position.
+func (p *Groot2Publisher) RemoveHook(position HookPosition, uid uint16) bool {
+	p.hooksMutex.Lock()
+	hook, ok := p.hooksOf(position)[uid]
+	if ok {
+		delete(p.hooksOf(position), uid)
+		node := p.nodesByUID[uid]
+		if position == HookPosition_POST {
+			node.SetPostTickFunction(nil)
+		} else {
+			node.SetPreTickFunction(nil)
+		}
+	}
+	p.hooksMutex.Unlock()
+	if !ok {
+		return false
+	}
+
+	// Disable breakpoint, if it was interactive and blocked
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if hook.Mode == HookMode_BREAKPOINT {
+		hook.Enabled = false
+		hook.wakeup.Broadcast()
+	}
+	return true
+}
+
+func (p *Groot2Publisher) RemoveAllHooks() {
+	p.hooksMutex.Lock()
+	pre, post := sortedUIDs(p.preHooks), sortedUIDs(p.postHooks)
+	p.hooksMutex.Unlock()
+	for _, uid := range pre {
+		p.RemoveHook(HookPosition_PRE, uid)
+	}
+	for _, uid := range post {
+		p.RemoveHook(HookPosition_POST, uid)
+	}
+}
+
+// EnableAllHooks enables or disables all the hooks; the disabled breakpoints are unlocked.
+func (p *Groot2Publisher) EnableAllHooks(enable bool) {
+	p.hooksMutex.Lock()
+	var hooks []*Hook
+	for _, hook := range p.preHooks {
+		hooks = append(hooks, hook)
+	}
+	for _, hook := range p.postHooks {
+		hooks = append(hooks, hook)
+	}
+	p.hooksMutex.Unlock()
+	for _, hook := range hooks {
+		hook.mutex.Lock()
+		hook.Enabled = enable
+		if !enable && hook.Mode == HookMode_BREAKPOINT {
+			hook.wakeup.Broadcast()
+		}
+		hook.mutex.Unlock()
+	}
+}
@@ -0,0 +1,228 @@
+package loggers
+
+import (
+	"fmt"
+	"github.com/gorustyt/go-behavior/core"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// freePort returns a port that is free for the publisher, together with port+1
+func freePort(t *testing.T) int {
+	t.Helper()
+	for i := 0; i < 10; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		port := l.Addr().(*net.TCPAddr).Port
+		l.Close()
+		l, err = net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port+1)))
+		if err != nil {
+			continue
+		}
+		l.Close()
+		return port
+	}
+	t.Fatal("no free port")
+	return 0
+}
+
+// newGroot2TestTree returns a tree with a single action, that fails unless a hook replaces its result
+func newGroot2TestTree(t *testing.T) (*core.Tree, *int) {
+	t.Helper()
+	ticks := new(int)
+	f := core.NewBehaviorTreeFactory()
+	f.RegisterSimpleAction("Fail", func(node core.ITreeNode, status ...core.NodeStatus) core.NodeStatus {
+		*ticks++
+		return core.NodeStatus_FAILURE
+	})
+	err := f.RegisterBehaviorTreeFromText(`
+<root BTCPP_format="4">
+	<BehaviorTree ID="Main">
+		<Fail name="action"/>
+	</BehaviorTree>
+</root>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := f.CreateTree("Main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree, ticks
+}
+
+func newGroot2TestPair(t *testing.T, tree *core.Tree) (*Groot2Publisher, *Groot2Client) {
+	t.Helper()
+	port := freePort(t)
+	publisher, err := NewGroot2Publisher(tree, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { publisher.Close() })
+	client, err := NewGroot2Client("127.0.0.1", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return publisher, client
+}
+
+func TestGroot2PublisherFullTreeAndStatus(t *testing.T) {
+	tree, _ := newGroot2TestTree(t)
+	_, client := newGroot2TestPair(t, tree)
+	uid := tree.Root().UID()
+
+	xml, err := client.FullTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<BehaviorTree ID="Main"`, `name="action"`, `_uid="` + strconv.Itoa(int(uid)) + `"`} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("the tree XML doesn't contain %v:\n%v", want, xml)
+		}
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := status[uid].Status; got != core.NodeStatus_IDLE {
+		t.Errorf("status before the tick = %v, want IDLE", got)
+	}
+
+	tree.Root().ExecuteTick()
+	status, err = client.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := status[uid].Status; got != core.NodeStatus_FAILURE {
+		t.Errorf("status after the tick = %v, want FAILURE", got)
+	}
+
+	if _, err := client.Request(RequestType('Z')); err == nil {
+		t.Error("an unknown request didn't fail")
+	}
+}
+
+func TestGroot2PublisherBlackboard(t *testing.T) {
+	tree, _ := newGroot2TestTree(t)
+	subtree := tree.Subtrees[0]
+	subtree.Blackboard.Set("answer", 42)
+	subtree.Blackboard.Set("greeting", "hello")
+	_, client := newGroot2TestPair(t, tree)
+
+	dump, err := client.Blackboards(subtree.InstanceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb, ok := dump[subtree.InstanceName].(map[string]any)
+	if !ok {
+		t.Fatalf("missing blackboard [%v] in %v", subtree.InstanceName, dump)
+	}
+	if got := fmt.Sprint(bb["answer"]); got != "42" {
+		t.Errorf("answer = %v, want 42", bb["answer"])
+	}
+	if got := bb["greeting"]; got != "hello" {
+		t.Errorf("greeting = %v, want hello", got)
+	}
+
+	dump, err = client.Blackboards("unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dump) != 0 {
+		t.Errorf("dump of an unknown subtree = %v, want empty", dump)
+	}
+}
+
+func TestGroot2PublisherReplaceHook(t *testing.T) {
+	tree, ticks := newGroot2TestTree(t)
+	_, client := newGroot2TestPair(t, tree)
+	uid := tree.Root().UID()
+
+	hook := NewHook()
+	hook.NodeUID = uid
+	hook.Mode = HookMode_REPLACE
+	hook.DesiredStatus = core.NodeStatus_SUCCESS
+	if err := client.InsertHooks(hook); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := client.Hooks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].NodeUID != uid || hooks[0].Mode != HookMode_REPLACE {
+		t.Fatalf("hooks = %v, want the replace hook of node %v", hooks, uid)
+	}
+
+	if status := tree.Root().ExecuteTick(); status != core.NodeStatus_SUCCESS {
+		t.Errorf("status with the hook = %v, want SUCCESS", status)
+	}
+	if *ticks != 0 {
+		t.Errorf("the replaced node was ticked %v times", *ticks)
+	}
+
+	if err := client.RemoveHook(HookPosition_PRE, uid); err != nil {
+		t.Fatal(err)
+	}
+	if hooks, _ := client.Hooks(); len(hooks) != 0 {
+		t.Errorf("hooks after RemoveHook = %v, want none", hooks)
+	}
+	tree.Root().ResetStatus()
+	if status := tree.Root().ExecuteTick(); status != core.NodeStatus_FAILURE {
+		t.Errorf("status without the hook = %v, want FAILURE", status)
+	}
+}
+
+func TestGroot2PublisherBreakpoint(t *testing.T) {
+	tree, _ := newGroot2TestTree(t)
+	_, client := newGroot2TestPair(t, tree)
+	uid := tree.Root().UID()
+
+	hook := NewHook()
+	hook.NodeUID = uid
+	if err := client.InsertHooks(hook); err != nil {
+		t.Fatal(err)
+	}
+	// give the subscription time to reach the publisher, or the notification is lost
+	time.Sleep(50 * time.Millisecond)
+
+	result := make(chan core.NodeStatus, 1)
+	go func() { result <- tree.Root().ExecuteTick() }()
+
+	reached, err := client.WaitBreakpoint(2 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reached != uid {
+		t.Fatalf("breakpoint reached by node %v, want %v", reached, uid)
+	}
+	select {
+	case status := <-result:
+		t.Fatalf("the tick returned %v before the breakpoint was unlocked", status)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := client.UnlockBreakpoint(HookPosition_PRE, uid, core.NodeStatus_SUCCESS, true); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case status := <-result:
+		if status != core.NodeStatus_SUCCESS {
+			t.Errorf("status after the unlock = %v, want SUCCESS", status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("the tick is still blocked after the unlock")
+	}
+	if hooks, _ := client.Hooks(); len(hooks) != 0 {
+		t.Errorf("hooks after the unlock with remove = %v, want none", hooks)
+	}
+	if err := client.UnlockBreakpoint(HookPosition_PRE, uid, core.NodeStatus_SUCCESS, true); err == nil {
+		t.Error("unlocking a removed breakpoint didn't fail")
+	}
+}
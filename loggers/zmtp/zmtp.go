@@ -0,0 +1,620 @@
+// Package zmtp is a minimal, pure Go implementation of the ZeroMQ Message Transport Protocol
+// (ZMTP 3.0, NULL security mechanism), over TCP.
+//
+// It implements only the socket types needed to talk with Groot2: REQ/REP and PUB/SUB.
+// A Socket either listens (Listen) and accepts any number of peers, or connects (Dial) to a single peer.
+package zmtp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+type SocketType string
+
+const (
+	REQ SocketType = "REQ"
+	REP SocketType = "REP"
+	PUB SocketType = "PUB"
+	SUB SocketType = "SUB"
+)
+
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+
+	greetingSize     = 64
+	handshakeTimeout = 5 * time.Second
+	writeTimeout     = 5 * time.Second
+	// frames bigger than this are considered a protocol error
+	maxFrameSize = 256 << 20
+)
+
+var ErrClosed = errors.New("zmtp: socket closed")
+
+// compatible lists the socket types that each socket type accepts as peer
+var compatible = map[SocketType][]SocketType{
+	REQ: {REP, "ROUTER"},
+	REP: {REQ, "DEALER"},
+	PUB: {SUB, "XSUB"},
+	SUB: {PUB, "XPUB"},
+}
+
+type message struct {
+	peer   *peer
+	frames [][]byte
+}
+
+type peer struct {
+	conn        net.Conn
+	reader      *bufio.Reader
+	writeMutex  sync.Mutex
+	remoteType  SocketType
+	subsMutex   sync.Mutex
+	subscribers [][]byte
+}
+
+// Socket is a ZMTP socket. Send and Recv must not be called concurrently with themselves,
+// but a goroutine can Send while another one is blocked in Recv (PUB/SUB).
+type Socket struct {
+	socketType SocketType
+	listener   net.Listener
+
+	mutex    sync.Mutex
+	peers    map[*peer]struct{}
+	incoming chan message
+	done     chan struct{}
+	err      error
+	once     sync.Once
+
+	// REP: the envelope and the peer of the request waiting for a reply
+	pending         *peer
+	pendingEnvelope [][]byte
+}
+
+func newSocket(socketType SocketType) (*Socket, error) {
+	if _, ok := compatible[socketType]; !ok {
+		return nil, fmt.Errorf("zmtp: unsupported socket type [%v]", socketType)
+	}
+	return &Socket{
+		socketType: socketType,
+		peers:      map[*peer]struct{}{},
+		incoming:   make(chan message),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// Listen creates a socket that accepts the connections of any number of peers.
+// The address has the form "host:port", as in net.Listen.
+func Listen(socketType SocketType, address string) (*Socket, error) {
+	s, err := newSocket(socketType)
+	if err != nil {
+		return nil, err
+	}
+	s.listener, err = net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	go s.acceptLoop()
+	return s, nil
+}
+
+// Dial creates a socket connected to a single peer.
+// If the connection is lost, Recv and Send return an error.
+func Dial(socketType SocketType, address string) (*Socket, error) {
+	s, err := newSocket(socketType)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("tcp", address, handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.handshake(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s.peers[p] = struct{}{}
+	go s.readLoop(p)
+	return s, nil
+}
+
+// Addr returns the address the socket is listening on, or the address of the peer for dialed sockets
+func (s *Socket) Addr() net.Addr {
+	if s.listener != nil {
+		return s.listener.Addr()
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for p := range s.peers {
+		return p.conn.RemoteAddr()
+	}
+	return nil
+}
+
+func (s *Socket) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.closeWithError(err)
+			return
+		}
+		go func() {
+			p, err := s.handshake(conn)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			s.mutex.Lock()
+			select {
+			case <-s.done:
+				s.mutex.Unlock()
+				conn.Close()
+				return
+			default:
+			}
+			s.peers[p] = struct{}{}
+			s.mutex.Unlock()
+			s.readLoop(p)
+		}()
+	}
+}
+
+// Close closes the listener and the connections with all the peers
+func (s *Socket) Close() error {
+	s.closeWithError(ErrClosed)
+	return nil
+}
+
+func (s *Socket) closeWithError(err error) {
+	s.once.Do(func() {
+		s.mutex.Lock()
+		s.err = err
+		close(s.done)
+		if s.listener != nil {
+			s.listener.Close()
+		}
+		for p := range s.peers {
+			p.conn.Close()
+		}
+		s.peers = map[*peer]struct{}{}
+		s.mutex.Unlock()
+	})
+}
+
+func (s *Socket) closedError() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
+
+func (s *Socket) removePeer(p *peer) {
+	p.conn.Close()
+	s.mutex.Lock()
+	delete(s.peers, p)
+	if s.pending == p {
+		s.pending = nil
+		s.pendingEnvelope = nil
+	}
+	s.mutex.Unlock()
+}
+
+//----------------------------------------------------------------------
+
+func (s *Socket) handshake(conn net.Conn) (*peer, error) {
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	var greeting [greetingSize]byte
+	greeting[0] = 0xFF
+	greeting[9] = 0x7F
+	greeting[10] = 3 // version 3.0
+	greeting[11] = 0
+	copy(greeting[12:32], "NULL")
+	_, err := conn.Write(greeting[:])
+	if err != nil {
+		return nil, err
+	}
+
+	p := &peer{conn: conn, reader: bufio.NewReader(conn)}
+	var remote [greetingSize]byte
+	_, err = io.ReadFull(p.reader, remote[:])
+	if err != nil {
+		return nil, err
+	}
+	if remote[0] != 0xFF || remote[9] != 0x7F {
+		return nil, fmt.Errorf("zmtp: invalid greeting signature")
+	}
+	if remote[10] < 3 {
+		return nil, fmt.Errorf("zmtp: unsupported protocol version %v.%v", remote[10], remote[11])
+	}
+	mechanism := string(bytes.TrimRight(remote[12:32], "\x00"))
+	if mechanism != "NULL" {
+		return nil, fmt.Errorf("zmtp: unsupported security mechanism [%v]", mechanism)
+	}
+
+	err = p.writeCommand("READY", encodeMetadata(map[string]string{"Socket-Type": string(s.socketType)}))
+	if err != nil {
+		return nil, err
+	}
+	name, body, err := p.readCommand()
+	if err != nil {
+		return nil, err
+	}
+	if name == "ERROR" {
+		return nil, fmt.Errorf("zmtp: handshake refused by the peer")
+	}
+	if name != "READY" {
+		return nil, fmt.Errorf("zmtp: expected READY, received [%v]", name)
+	}
+	metadata, err := decodeMetadata(body)
+	if err != nil {
+		return nil, err
+	}
+	p.remoteType = SocketType(metadata["Socket-Type"])
+	for _, t := range compatible[s.socketType] {
+		if t == p.remoteType {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("zmtp: a %v socket can't talk with a %v socket", s.socketType, p.remoteType)
+}
+
+func encodeMetadata(properties map[string]string) []byte {
+	var buf bytes.Buffer
+	for name, value := range properties {
+		buf.WriteByte(byte(len(name)))
+		buf.WriteString(name)
+		binary.Write(&buf, binary.BigEndian, uint32(len(value)))
+		buf.WriteString(value)
+	}
+	return buf.Bytes()
+}
+
+func decodeMetadata(data []byte) (map[string]string, error) {
+	res := map[string]string{}
+	for len(data) > 0 {
+		nameSize := int(data[0])
+		if len(data) < 1+nameSize+4 {
+			return nil, fmt.Errorf("zmtp: malformed metadata")
+		}
+		name := string(data[1 : 1+nameSize])
+		data = data[1+nameSize:]
+		valueSize := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint32(len(data)) < valueSize {
+			return nil, fmt.Errorf("zmtp: malformed metadata")
+		}
+		res[name] = string(data[:valueSize])
+		data = data[valueSize:]
+	}
+	return res, nil
+}
+
+//----------------------------------------------------------------------
+
+func (p *peer) readFrame() (flags byte, body []byte, err error) {
+	flags, err = p.reader.ReadByte()
+	if err != nil {
+		return
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var buf [8]byte
+		_, err = io.ReadFull(p.reader, buf[:])
+		if err != nil {
+			return
+		}
+		size = binary.BigEndian.Uint64(buf[:])
+	} else {
+		var b byte
+		b, err = p.reader.ReadByte()
+		if err != nil {
+			return
+		}
+		size = uint64(b)
+	}
+	if size > maxFrameSize {
+		return flags, nil, fmt.Errorf("zmtp: frame of %v bytes is too big", size)
+	}
+	body = make([]byte, size)
+	_, err = io.ReadFull(p.reader, body)
+	return
+}
+
+func (p *peer) readCommand() (name string, body []byte, err error) {
+	flags, frame, err := p.readFrame()
+	if err != nil {
+		return
+	}
+	if flags&flagCommand == 0 {
+		return "", nil, fmt.Errorf("zmtp: expected a command, received a message")
+	}
+	return parseCommand(frame)
+}
+
+func parseCommand(frame []byte) (name string, body []byte, err error) {
+	if len(frame) == 0 || len(frame) < 1+int(frame[0]) {
+		return "", nil, fmt.Errorf("zmtp: malformed command")
+	}
+	nameSize := int(frame[0])
+	return string(frame[1 : 1+nameSize]), frame[1+nameSize:], nil
+}
+
+func appendFrame(buf []byte, flags byte, body []byte) []byte {
+	if len(body) > 255 {
+		buf = append(buf, flags|flagLong)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(body)))
+	} else {
+		buf = append(buf, flags, byte(len(body)))
+	}
+	return append(buf, body...)
+}
+
+func (p *peer) write(data []byte) error {
+	p.writeMutex.Lock()
+	defer p.writeMutex.Unlock()
+	p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := p.conn.Write(data)
+	return err
+}
+
+func (p *peer) writeCommand(name string, body []byte) error {
+	frame := append([]byte{byte(len(name))}, name...)
+	return p.write(appendFrame(nil, flagCommand, append(frame, body...)))
+}
+
+func (p *peer) writeMessage(frames [][]byte) error {
+	var buf []byte
+	for i, frame := range frames {
+		var flags byte
+		if i < len(frames)-1 {
+			flags = flagMore
+		}
+		buf = appendFrame(buf, flags, frame)
+	}
+	return p.write(buf)
+}
+
+// readMessage returns the next message, handling the commands received in the meantime
+func (p *peer) readMessage() ([][]byte, error) {
+	var frames [][]byte
+	for {
+		flags, body, err := p.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		if flags&flagCommand != 0 {
+			err = p.handleCommand(body)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		frames = append(frames, body)
+		if flags&flagMore == 0 {
+			return frames, nil
+		}
+	}
+}
+
+func (p *peer) handleCommand(frame []byte) error {
+	name, body, err := parseCommand(frame)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "PING":
+		// the 2 bytes TTL are followed by the context, that must be returned in the PONG
+		if len(body) < 2 {
+			return fmt.Errorf("zmtp: malformed PING")
+		}
+		return p.writeCommand("PONG", body[2:])
+	case "SUBSCRIBE":
+		p.subscribe(body)
+	case "CANCEL":
+		p.unsubscribe(body)
+	case "ERROR":
+		return fmt.Errorf("zmtp: error received from the peer")
+	}
+	return nil
+}
+
+func (p *peer) subscribe(topic []byte) {
+	p.subsMutex.Lock()
+	defer p.subsMutex.Unlock()
+	p.subscribers = append(p.subscribers, append([]byte{}, topic...))
+}
+
+func (p *peer) unsubscribe(topic []byte) {
+	p.subsMutex.Lock()
+	defer p.subsMutex.Unlock()
+	for i, t := range p.subscribers {
+		if bytes.Equal(t, topic) {
+			p.subscribers = append(p.subscribers[:i], p.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (p *peer) subscribed(frames [][]byte) bool {
+	var first []byte
+	if len(frames) > 0 {
+		first = frames[0]
+	}
+	p.subsMutex.Lock()
+	defer p.subsMutex.Unlock()
+	for _, t := range p.subscribers {
+		if bytes.HasPrefix(first, t) {
+			return true
+		}
+	}
+	return false
+}
+
+//----------------------------------------------------------------------
+
+func (s *Socket) readLoop(p *peer) {
+	for {
+		frames, err := p.readMessage()
+		if err != nil {
+			s.removePeer(p)
+			if s.listener == nil {
+				// a dialed socket is useless without its only peer
+				s.closeWithError(err)
+			}
+			return
+		}
+		if s.socketType == PUB {
+			// in ZMTP 3.0 the subscriptions are messages, where the first byte is 1 (subscribe) or 0 (cancel)
+			if len(frames) == 1 && len(frames[0]) > 0 {
+				switch frames[0][0] {
+				case 1:
+					p.subscribe(frames[0][1:])
+				case 0:
+					p.unsubscribe(frames[0][1:])
+				}
+			}
+			continue
+		}
+		select {
+		case s.incoming <- message{peer: p, frames: frames}:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Recv blocks until a message is received. Not available for PUB sockets.
+// A REP socket must Send the reply before receiving the next request.
+func (s *Socket) Recv() ([][]byte, error) {
+	return s.recv(nil)
+}
+
+// RecvTimeout is like Recv, but returns os.ErrDeadlineExceeded if nothing is received within timeout.
+func (s *Socket) RecvTimeout(timeout time.Duration) ([][]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	return s.recv(timer.C)
+}
+
+func (s *Socket) recv(timeout <-chan time.Time) ([][]byte, error) {
+	if s.socketType == PUB {
+		return nil, fmt.Errorf("zmtp: a PUB socket can't receive")
+	}
+	for {
+		var msg message
+		select {
+		case msg = <-s.incoming:
+		case <-timeout:
+			return nil, os.ErrDeadlineExceeded
+		case <-s.done:
+			return nil, s.closedError()
+		}
+		switch s.socketType {
+		case REP:
+			// the envelope is made of the routing frames, up to the empty delimiter
+			for i, frame := range msg.frames {
+				if len(frame) == 0 {
+					s.mutex.Lock()
+					s.pending = msg.peer
+					s.pendingEnvelope = msg.frames[:i+1]
+					s.mutex.Unlock()
+					return msg.frames[i+1:], nil
+				}
+			}
+			// malformed request, without delimiter: drop it
+		case REQ:
+			if len(msg.frames) > 0 && len(msg.frames[0]) == 0 {
+				return msg.frames[1:], nil
+			}
+		default:
+			return msg.frames, nil
+		}
+	}
+}
+
+// Send writes a message made of one or more frames.
+// A PUB socket sends the message to all the peers subscribed to its first frame.
+func (s *Socket) Send(frames ...[]byte) error {
+	select {
+	case <-s.done:
+		return s.closedError()
+	default:
+	}
+	switch s.socketType {
+	case REP:
+		s.mutex.Lock()
+		p, envelope := s.pending, s.pendingEnvelope
+		s.pending, s.pendingEnvelope = nil, nil
+		s.mutex.Unlock()
+		if p == nil {
+			return fmt.Errorf("zmtp: REP socket has no request to reply to")
+		}
+		err := p.writeMessage(append(append([][]byte{}, envelope...), frames...))
+		if err != nil {
+			s.removePeer(p)
+		}
+		return err
+	case REQ:
+		return s.sendToAll(append([][]byte{{}}, frames...))
+	case PUB:
+		s.mutex.Lock()
+		peers := make([]*peer, 0, len(s.peers))
+		for p := range s.peers {
+			peers = append(peers, p)
+		}
+		s.mutex.Unlock()
+		for _, p := range peers {
+			if !p.subscribed(frames) {
+				continue
+			}
+			// a slow or dead subscriber is disconnected, instead of blocking the publisher
+			if p.writeMessage(frames) != nil {
+				s.removePeer(p)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("zmtp: a %v socket can't send messages", s.socketType)
+	}
+}
+
+func (s *Socket) sendToAll(frames [][]byte) error {
+	s.mutex.Lock()
+	peers := make([]*peer, 0, len(s.peers))
+	for p := range s.peers {
+		peers = append(peers, p)
+	}
+	s.mutex.Unlock()
+	if len(peers) == 0 {
+		return fmt.Errorf("zmtp: not connected")
+	}
+	for _, p := range peers {
+		err := p.writeMessage(frames)
+		if err != nil {
+			s.removePeer(p)
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe tells the peers of a SUB socket to send the messages starting with topic.
+// An empty topic matches all the messages.
+func (s *Socket) Subscribe(topic []byte) error {
+	if s.socketType != SUB {
+		return fmt.Errorf("zmtp: only SUB sockets can subscribe")
+	}
+	return s.sendToAll([][]byte{append([]byte{1}, topic...)})
+}